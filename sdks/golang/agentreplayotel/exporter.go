@@ -0,0 +1,170 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agentreplayotel exports spans recorded with the OpenTelemetry Go
+// SDK to Agentreplay, for services that are already instrumented with
+// OpenTelemetry.
+//
+// Example:
+//
+//	client := agentreplay.NewClient(url, tenantID, agentreplay.WithAgentID(1))
+//	provider := sdktrace.NewTracerProvider(
+//	    sdktrace.WithBatcher(agentreplayotel.NewSpanExporter(client)),
+//	)
+//	defer provider.Shutdown(ctx)
+package agentreplayotel
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	agentreplay "github.com/sushanthpy/agentreplay/sdks/golang"
+)
+
+// SpanExporter is an OpenTelemetry SpanExporter that converts finished
+// spans to SpanInputs and sends them with Client.IngestBatch.
+//
+// Span and resource attributes are copied as-is, with span attributes
+// winning, so GenAI semantic-convention attributes (gen_ai.*) reach
+// Agentreplay unchanged. The OTel trace and span IDs become the TraceID and
+// SpanID. The span type is guessed with agentreplay.SpanTypeFromOTel unless
+// a span_type attribute is set, and spans with an error status become
+// SpanTypeError.
+//
+// tenant_id, project_id and agent_id default to the client's. session_id
+// defaults to the low 63 bits of the trace ID, so all spans of a trace land
+// in one session; set a session_id attribute to group traces differently.
+type SpanExporter struct {
+	client  *agentreplay.Client
+	stopped atomic.Bool
+}
+
+var _ sdktrace.SpanExporter = (*SpanExporter)(nil)
+
+// NewSpanExporter returns a SpanExporter sending through client.
+func NewSpanExporter(client *agentreplay.Client) *SpanExporter {
+	return &SpanExporter{client: client}
+}
+
+// ExportSpans converts spans and ingests them as one batch. It does nothing
+// after Shutdown.
+func (e *SpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if e.stopped.Load() || len(spans) == 0 {
+		return nil
+	}
+	inputs := make([]agentreplay.SpanInput, len(spans))
+	for i, span := range spans {
+		inputs[i] = e.convert(span)
+	}
+	_, err := e.client.IngestBatch(ctx, inputs)
+	return err
+}
+
+// Shutdown stops the exporter. It does not close the client, which may be
+// shared with other code; call Client.Close for that.
+func (e *SpanExporter) Shutdown(ctx context.Context) error {
+	e.stopped.Store(true)
+	return ctx.Err()
+}
+
+// convert maps an OpenTelemetry span to a SpanInput.
+func (e *SpanExporter) convert(span sdktrace.ReadOnlySpan) agentreplay.SpanInput {
+	attributes := make(map[string]string)
+	if res := span.Resource(); res != nil {
+		copyAttributes(attributes, res.Attributes())
+	}
+	copyAttributes(attributes, span.Attributes())
+
+	sc := span.SpanContext()
+	setDefault(attributes, "tenant_id", strconv.FormatInt(e.client.TenantID(), 10))
+	setDefault(attributes, "project_id", strconv.FormatInt(e.client.ProjectID(), 10))
+	setDefault(attributes, "agent_id", strconv.FormatInt(e.client.AgentID(), 10))
+	setDefault(attributes, "session_id", strconv.FormatInt(sessionID(sc.TraceID()), 10))
+
+	if scope := span.InstrumentationScope(); scope.Name != "" {
+		setDefault(attributes, "otel.scope.name", scope.Name)
+	}
+	status := span.Status()
+	if status.Code == codes.Error {
+		attributes["otel.status_code"] = "ERROR"
+		if status.Description != "" {
+			attributes["otel.status_description"] = status.Description
+		}
+	}
+	if _, ok := attributes["span_type"]; !ok {
+		spanType := agentreplay.SpanTypeFromOTel(agentreplay.OTelSpanKind(span.SpanKind()), attributes)
+		if status.Code == codes.Error {
+			spanType = agentreplay.SpanTypeError
+		}
+		attributes["span_type"] = strconv.Itoa(int(spanType))
+	}
+
+	start := span.StartTime().UnixMicro()
+	end := span.EndTime().UnixMicro()
+	if end < start {
+		end = start
+	}
+	attributes["duration_us"] = strconv.FormatInt(end-start, 10)
+
+	input := agentreplay.SpanInput{
+		SpanID:     sc.SpanID().String(),
+		TraceID:    sc.TraceID().String(),
+		Name:       span.Name(),
+		StartTime:  start,
+		EndTime:    &end,
+		Attributes: attributes,
+	}
+	if parent := span.Parent(); parent.IsValid() {
+		parentID := parent.SpanID().String()
+		input.ParentSpanID = &parentID
+	}
+	for _, event := range span.Events() {
+		eventAttributes := make(map[string]string, len(event.Attributes))
+		copyAttributes(eventAttributes, event.Attributes)
+		input.Events = append(input.Events, agentreplay.SpanEvent{
+			Name:       event.Name,
+			Timestamp:  event.Time.UnixMicro(),
+			Attributes: eventAttributes,
+		})
+	}
+	return input
+}
+
+// sessionID derives a session ID from the low 63 bits of a trace ID, the
+// inverse of how the client maps numeric session IDs to OTLP trace IDs.
+func sessionID(traceID trace.TraceID) int64 {
+	return int64(binary.BigEndian.Uint64(traceID[8:]) & math.MaxInt64)
+}
+
+// copyAttributes copies OpenTelemetry attributes into dst as strings.
+func copyAttributes(dst map[string]string, kvs []attribute.KeyValue) {
+	for _, kv := range kvs {
+		dst[string(kv.Key)] = kv.Value.Emit()
+	}
+}
+
+// setDefault sets key to value unless it is already set.
+func setDefault(attributes map[string]string, key, value string) {
+	if _, ok := attributes[key]; !ok {
+		attributes[key] = value
+	}
+}
@@ -0,0 +1,531 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agentreplaytest provides an in-memory Agentreplay server for
+// testing code that uses the agentreplay client.
+//
+// Example:
+//
+//	server := agentreplaytest.NewMockServer()
+//	defer server.Close()
+//
+//	client := agentreplay.NewClient(server.URL(), 1)
+//	runAgent(ctx, client)
+//
+//	for _, span := range server.Spans() {
+//	    if span.Name == "tool-web_search" {
+//	        return // found it
+//	    }
+//	}
+//	t.Fatal("agent did not call web_search")
+package agentreplaytest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	agentreplay "github.com/sushanthpy/agentreplay/sdks/golang"
+)
+
+// MockServer is an in-memory stand-in for the Agentreplay server. It records
+// every span ingested through /api/v1/traces and serves them back through
+// the query, trace, tree, feedback, dataset and health endpoints. Only JSON
+// ingestion is supported; it does not advertise the otlp_protobuf capability.
+// It is safe for concurrent use.
+type MockServer struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	spans    []agentreplay.SpanInput
+	feedback map[string]int
+	datasets map[string][]agentreplay.DatasetEntry
+	version  string
+}
+
+// NewMockServer starts a MockServer. Call Close when done.
+func NewMockServer() *MockServer {
+	m := &MockServer{
+		feedback: make(map[string]int),
+		datasets: make(map[string][]agentreplay.DatasetEntry),
+		version:  "mock",
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/health", m.handleHealth)
+	mux.HandleFunc("POST /api/v1/traces", m.handleIngest)
+	mux.HandleFunc("GET /api/v1/traces", m.handleQuery)
+	mux.HandleFunc("GET /api/v1/traces/{id}", m.handleGetTrace)
+	mux.HandleFunc("PATCH /api/v1/traces/{id}", m.handlePatch)
+	mux.HandleFunc("DELETE /api/v1/traces/{id}", m.handleDeleteTrace)
+	mux.HandleFunc("GET /api/v1/traces/{id}/tree", m.handleTree)
+	mux.HandleFunc("POST /api/v1/traces/{id}/events", m.handleEvents)
+	mux.HandleFunc("POST /api/v1/traces/{id}/feedback", m.handleFeedback)
+	mux.HandleFunc("POST /api/v1/feedback/batch", m.handleFeedbackBatch)
+	mux.HandleFunc("GET /api/v1/datasets", m.handleListDatasets)
+	mux.HandleFunc("GET /api/v1/datasets/{name}", m.handleGetDataset)
+	mux.HandleFunc("POST /api/v1/datasets/{name}/add", m.handleDatasetAdd)
+	mux.HandleFunc("DELETE /api/v1/sessions/{id}", m.handleDeleteSession)
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+// URL returns the server's base URL, for use with agentreplay.NewClient.
+func (m *MockServer) URL() string {
+	return m.server.URL
+}
+
+// Close shuts the server down.
+func (m *MockServer) Close() {
+	m.server.Close()
+}
+
+// Spans returns a copy of every span received so far, in arrival order,
+// with any later patches and events applied.
+func (m *MockServer) Spans() []agentreplay.SpanInput {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	spans := make([]agentreplay.SpanInput, len(m.spans))
+	for i, span := range m.spans {
+		spans[i] = copySpan(span)
+	}
+	return spans
+}
+
+// Feedback returns the feedback submitted for each trace ID.
+func (m *MockServer) Feedback() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	feedback := make(map[string]int, len(m.feedback))
+	for id, value := range m.feedback {
+		feedback[id] = value
+	}
+	return feedback
+}
+
+// SetVersion sets the version reported by the health endpoint, which is
+// "mock" by default, for testing WithVersionCheck.
+func (m *MockServer) SetVersion(version string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.version = version
+}
+
+// Reset discards all recorded spans, feedback and dataset entries.
+func (m *MockServer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spans = nil
+	m.feedback = make(map[string]int)
+	m.datasets = make(map[string][]agentreplay.DatasetEntry)
+}
+
+func (m *MockServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	version := m.version
+	m.mu.Unlock()
+	writeJSON(w, http.StatusOK, agentreplay.HealthResponse{Status: "healthy", Version: version})
+}
+
+func (m *MockServer) handleIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "only JSON ingestion is supported", http.StatusUnsupportedMediaType)
+		return
+	}
+	var payload struct {
+		Spans []agentreplay.SpanInput `json:"spans"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := agentreplay.IngestResponse{Errors: []string{}}
+	var accepted []agentreplay.SpanInput
+	for i := range payload.Spans {
+		if err := payload.Spans[i].Validate(); err != nil {
+			resp.Rejected++
+			resp.Errors = append(resp.Errors, err.Error())
+			continue
+		}
+		accepted = append(accepted, payload.Spans[i])
+	}
+	resp.Accepted = len(accepted)
+
+	if r.URL.Query().Get("validate_only") != "true" {
+		m.mu.Lock()
+		m.spans = append(m.spans, accepted...)
+		m.mu.Unlock()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (m *MockServer) handleQuery(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	m.mu.Lock()
+	var traces []agentreplay.TraceView
+	for _, span := range m.spans {
+		if matches(span, query) {
+			traces = append(traces, traceView(span))
+		}
+	}
+	m.mu.Unlock()
+
+	total := len(traces)
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	writeJSON(w, http.StatusOK, agentreplay.QueryResponse{
+		Traces: append([]agentreplay.TraceView{}, traces[offset:end]...),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (m *MockServer) handleGetTrace(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	span, ok := m.find(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, traceView(*span))
+}
+
+func (m *MockServer) handleDeleteTrace(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := r.PathValue("id")
+	if _, ok := m.find(id); !ok {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+	m.remove(func(span agentreplay.SpanInput) bool { return span.SpanID == id })
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success", "message": "Trace deleted"})
+}
+
+func (m *MockServer) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := r.PathValue("id")
+	count := m.remove(func(span agentreplay.SpanInput) bool { return span.Attributes["session_id"] == id })
+	if count == 0 {
+		http.Error(w, "session not found or empty", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deleted": true, "session_id": id, "count": count})
+}
+
+func (m *MockServer) handlePatch(w http.ResponseWriter, r *http.Request) {
+	var patch struct {
+		Attributes   map[string]string `json:"attributes"`
+		ParentSpanID *string           `json:"parent_span_id"`
+	}
+	if err := decodeJSON(r, &patch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	span, ok := m.find(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+	for k, v := range patch.Attributes {
+		span.Attributes[k] = v
+	}
+	if patch.ParentSpanID != nil {
+		span.ParentSpanID = patch.ParentSpanID
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (m *MockServer) handleTree(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	span, ok := m.find(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, agentreplay.TraceTreeResponse{Root: m.tree(*span, map[string]bool{})})
+}
+
+func (m *MockServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Events []agentreplay.SpanEvent `json:"events"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	span, ok := m.find(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+	span.Events = append(span.Events, payload.Events...)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (m *MockServer) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Feedback int `json:"feedback"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	m.feedback[r.PathValue("id")] = payload.Feedback
+	m.mu.Unlock()
+	writeJSON(w, http.StatusOK, agentreplay.FeedbackResponse{Success: true, Message: "feedback recorded"})
+}
+
+func (m *MockServer) handleFeedbackBatch(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Items []agentreplay.FeedbackItem `json:"items"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	resp := agentreplay.FeedbackBatchResponse{Results: make([]agentreplay.FeedbackItemResult, len(payload.Items))}
+	for i, item := range payload.Items {
+		resp.Results[i] = agentreplay.FeedbackItemResult{TraceID: item.TraceID, Message: "trace not found"}
+		if _, ok := m.find(item.TraceID); ok {
+			m.feedback[item.TraceID] = item.Score
+			resp.Results[i] = agentreplay.FeedbackItemResult{TraceID: item.TraceID, Success: true, Message: "feedback recorded"}
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (m *MockServer) handleDatasetAdd(w http.ResponseWriter, r *http.Request) {
+	var entry agentreplay.DatasetEntry
+	if err := decodeJSON(r, &entry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entry.AddedAt = time.Now().Unix()
+	name := r.PathValue("name")
+	m.mu.Lock()
+	m.datasets[name] = append(m.datasets[name], entry)
+	m.mu.Unlock()
+	writeJSON(w, http.StatusOK, agentreplay.DatasetResponse{Success: true, DatasetName: name})
+}
+
+func (m *MockServer) handleListDatasets(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	datasets := make([]agentreplay.DatasetInfo, 0, len(m.datasets))
+	for name, entries := range m.datasets {
+		datasets = append(datasets, agentreplay.DatasetInfo{Name: name, EntryCount: len(entries)})
+	}
+	sort.Slice(datasets, func(i, j int) bool { return datasets[i].Name < datasets[j].Name })
+	writeJSON(w, http.StatusOK, map[string]interface{}{"datasets": datasets})
+}
+
+func (m *MockServer) handleGetDataset(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries, ok := m.datasets[name]
+	if !ok {
+		http.Error(w, "dataset not found", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	total := len(entries)
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	writeJSON(w, http.StatusOK, agentreplay.DatasetPage{
+		DatasetName: name,
+		Entries:     append([]agentreplay.DatasetEntry{}, entries[offset:end]...),
+		Total:       total,
+		Limit:       limit,
+		Offset:      offset,
+	})
+}
+
+// find returns the recorded span with the given ID. m.mu must be held.
+func (m *MockServer) find(id string) (*agentreplay.SpanInput, bool) {
+	for i := range m.spans {
+		if m.spans[i].SpanID == id {
+			return &m.spans[i], true
+		}
+	}
+	return nil, false
+}
+
+// remove drops every span matching match and returns how many were
+// dropped. m.mu must be held.
+func (m *MockServer) remove(match func(agentreplay.SpanInput) bool) int {
+	kept := m.spans[:0]
+	for _, span := range m.spans {
+		if !match(span) {
+			kept = append(kept, span)
+		}
+	}
+	removed := len(m.spans) - len(kept)
+	m.spans = kept
+	return removed
+}
+
+// tree builds the subtree rooted at span, ordered by start time. m.mu must
+// be held.
+func (m *MockServer) tree(span agentreplay.SpanInput, visited map[string]bool) agentreplay.TraceTreeNode {
+	visited[span.SpanID] = true
+	view := traceView(span)
+	node := agentreplay.TraceTreeNode{
+		EdgeID:     view.EdgeID,
+		SpanType:   view.SpanType,
+		DurationUs: view.DurationUs,
+		Metadata:   view.Metadata,
+	}
+	var children []agentreplay.SpanInput
+	for _, child := range m.spans {
+		if child.ParentSpanID != nil && *child.ParentSpanID == span.SpanID && !visited[child.SpanID] {
+			children = append(children, child)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].StartTime < children[j].StartTime })
+	for _, child := range children {
+		node.Children = append(node.Children, m.tree(child, visited))
+	}
+	return node
+}
+
+// matches reports whether span satisfies the query's ID, model, attribute
+// and span type filters.
+func matches(span agentreplay.SpanInput, query map[string][]string) bool {
+	for _, key := range []string{"project_id", "agent_id", "session_id", "environment"} {
+		if want := query[key]; len(want) > 0 && span.Attributes[key] != want[0] {
+			return false
+		}
+	}
+	if want := query["model"]; len(want) > 0 && span.Attributes["gen_ai.request.model"] != want[0] {
+		return false
+	}
+	for key, want := range query {
+		if name, ok := strings.CutPrefix(key, "attr."); ok && len(want) > 0 && span.Attributes[name] != want[0] {
+			return false
+		}
+	}
+	if want := query["span_type"]; len(want) > 0 && span.Attributes["span_type"] != want[0] {
+		return false
+	}
+	if want := query["span_types"]; len(want) > 0 && !slices.Contains(strings.Split(want[0], ","), span.Attributes["span_type"]) {
+		return false
+	}
+	return true
+}
+
+// traceView converts a recorded span into the form the query endpoints
+// return. All attributes are exposed as metadata.
+func traceView(span agentreplay.SpanInput) agentreplay.TraceView {
+	attrs := span.Attributes
+	view := agentreplay.TraceView{
+		EdgeID:      span.SpanID,
+		Name:        span.Name,
+		SpanType:    attrs["span_type"],
+		TimestampUs: span.StartTime,
+		Environment: attrs["environment"],
+		Metadata:    make(map[string]interface{}, len(attrs)),
+	}
+	if span.ParentSpanID != nil {
+		view.ParentSpanID = *span.ParentSpanID
+	}
+	view.TenantID, _ = strconv.ParseInt(attrs["tenant_id"], 10, 64)
+	view.ProjectID, _ = strconv.ParseInt(attrs["project_id"], 10, 64)
+	view.AgentID, _ = strconv.ParseInt(attrs["agent_id"], 10, 64)
+	view.SessionID, _ = strconv.ParseInt(attrs["session_id"], 10, 64)
+	view.DurationUs, _ = strconv.ParseInt(attrs["duration_us"], 10, 64)
+	view.TokenCount, _ = strconv.Atoi(attrs["token_count"])
+	for k, v := range attrs {
+		view.Metadata[k] = v
+	}
+	return view
+}
+
+// copySpan returns a deep copy of span.
+func copySpan(span agentreplay.SpanInput) agentreplay.SpanInput {
+	attributes := make(map[string]string, len(span.Attributes))
+	for k, v := range span.Attributes {
+		attributes[k] = v
+	}
+	span.Attributes = attributes
+	if span.ParentSpanID != nil {
+		parent := *span.ParentSpanID
+		span.ParentSpanID = &parent
+	}
+	if span.EndTime != nil {
+		end := *span.EndTime
+		span.EndTime = &end
+	}
+	span.Events = append([]agentreplay.SpanEvent(nil), span.Events...)
+	return span
+}
+
+// decodeJSON decodes the request body into v, gunzipping it if needed.
+func decodeJSON(r *http.Request, v interface{}) error {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		body = zr
+	}
+	return json.NewDecoder(body).Decode(v)
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// archivePageSize is the page size used when paging through traces for an
+// archive export.
+const archivePageSize = 500
+
+// archiveManifestName is the name of the manifest entry in an agent archive.
+const archiveManifestName = "manifest.json"
+
+// ArchiveManifest describes the contents of an agent archive.
+type ArchiveManifest struct {
+	AgentID    int64   `json:"agent_id"`
+	ExportedAt int64   `json:"exported_at"`
+	Sessions   []int64 `json:"sessions"`
+	Spans      int     `json:"spans"`
+}
+
+// archiveSessionName returns the archive entry name for a session's spans.
+func archiveSessionName(sessionID int64) string {
+	return fmt.Sprintf("sessions/%d.jsonl", sessionID)
+}
+
+// ExportAgentArchive writes every session of an agent to w as a gzip-compressed
+// tar archive, with one JSONL file of TraceViews per session plus a manifest,
+// suitable for ImportArchive.
+//
+// The agent's traces are paged from the server in a single pass and grouped
+// by session as they arrive, so the manifest describes exactly the spans
+// written. Each session's JSONL is held in memory until the pass completes,
+// since tar entries need their size up front. The API has no read endpoints
+// for feedback or dataset membership, so those are exported only as far as
+// the server includes them in trace metadata. Cancelling ctx stops the
+// export between pages.
+func (c *Client) ExportAgentArchive(ctx context.Context, agentID int64, w io.Writer) error {
+	manifest := ArchiveManifest{
+		AgentID:    agentID,
+		ExportedAt: nowMicroseconds(),
+	}
+	sessions := make(map[int64]*bytes.Buffer)
+	for trace, err := range c.IterateTraces(ctx, &QueryFilter{AgentID: &agentID, Limit: archivePageSize}) {
+		if err != nil {
+			return fmt.Errorf("failed to export traces: %w", err)
+		}
+		buf, ok := sessions[trace.SessionID]
+		if !ok {
+			buf = new(bytes.Buffer)
+			sessions[trace.SessionID] = buf
+			manifest.Sessions = append(manifest.Sessions, trace.SessionID)
+		}
+		if err := json.NewEncoder(buf).Encode(trace); err != nil {
+			return fmt.Errorf("failed to export session %d: %w", trace.SessionID, err)
+		}
+		manifest.Spans++
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, sessionID := range manifest.Sessions {
+		if err := writeArchiveEntry(tw, archiveSessionName(sessionID), sessions[sessionID].Bytes()); err != nil {
+			return err
+		}
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writeArchiveEntry(tw, archiveManifestName, manifestBytes); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return gz.Close()
+}
+
+// writeArchiveEntry writes a single file into the archive.
+func writeArchiveEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
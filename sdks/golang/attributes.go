@@ -0,0 +1,125 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// AttributeUpdate is a partial update of a span's attributes for
+// AddAttributes.
+//
+// Scalar attributes replace any existing value under the same key, as with
+// UpdateSpan. Indexed list attributes, stored one entry per key as
+// <prefix>.0, <prefix>.1 and so on (the way CreateTraceOptions.Tags is
+// stored under "tag"), are appended to: new entries are numbered after the
+// span's existing ones, so earlier entries survive. Naming a prefix in
+// ReplaceLists replaces that list instead.
+type AttributeUpdate struct {
+	// Attributes are scalar attributes, each replacing any existing value.
+	Attributes map[string]string
+	// Lists maps a list prefix, such as "tag", to entries to add to it.
+	Lists map[string][]string
+	// ReplaceLists names prefixes in Lists whose existing entries are
+	// replaced rather than appended to. The server cannot delete
+	// attributes, so existing entries beyond the new list's length are
+	// cleared to "", which readers should treat as absent.
+	ReplaceLists []string
+}
+
+// AddAttributes applies update to an existing span, merging scalars and
+// lists as described on AttributeUpdate. Updating lists reads the span's
+// current attributes first with GetTrace, so concurrent appends to the same
+// list of the same span can still overwrite each other. The SDK's identity
+// attributes cannot be changed, and the redactor, if any, is applied.
+//
+// Example:
+//
+//	err := client.AddAttributes(ctx, edgeID, agentreplay.AttributeUpdate{
+//	    Attributes: map[string]string{"review.status": "done"},
+//	    Lists:      map[string][]string{"tag": {"reviewed"}},
+//	})
+func (c *Client) AddAttributes(ctx context.Context, edgeID string, update AttributeUpdate) error {
+	if err := checkReservedAttributes(update.Attributes); err != nil {
+		return err
+	}
+	attrs := maps.Clone(update.Attributes)
+	if attrs == nil {
+		attrs = make(map[string]string)
+	}
+
+	if len(update.Lists) > 0 {
+		trace, err := c.GetTrace(ctx, edgeID)
+		if err != nil {
+			return fmt.Errorf("failed to read span attributes: %w", err)
+		}
+		replace := make(map[string]bool, len(update.ReplaceLists))
+		for _, prefix := range update.ReplaceLists {
+			replace[prefix] = true
+		}
+		for prefix, values := range update.Lists {
+			if prefix == "" || strings.HasSuffix(prefix, ".") {
+				return &ValidationError{Field: "Lists", Reason: fmt.Sprintf("invalid list prefix %q", prefix)}
+			}
+			existing := listLength(trace.Metadata, prefix)
+			start := existing
+			if replace[prefix] {
+				start = 0
+				for i := len(values); i < existing; i++ {
+					attrs[listKey(prefix, i)] = ""
+				}
+			}
+			for i, value := range values {
+				key := listKey(prefix, start+i)
+				if _, ok := update.Attributes[key]; ok {
+					return &ValidationError{Field: "Attributes", Reason: fmt.Sprintf("%q is also written by list %q", key, prefix)}
+				}
+				attrs[key] = value
+			}
+		}
+	}
+	return c.patchAttributes(ctx, edgeID, attrs)
+}
+
+// AddTags appends tags to an existing span's tag.N attributes, keeping the
+// tags it already has. It is shorthand for AddAttributes with a "tag" list.
+func (c *Client) AddTags(ctx context.Context, edgeID string, tags ...string) error {
+	return c.AddAttributes(ctx, edgeID, AttributeUpdate{Lists: map[string][]string{"tag": tags}})
+}
+
+// listKey returns the attribute key of entry i of an indexed list.
+func listKey(prefix string, i int) string {
+	return prefix + "." + strconv.Itoa(i)
+}
+
+// listLength returns the number of entries in the indexed list under prefix:
+// one more than its highest index, or zero if it has none.
+func listLength(metadata map[string]interface{}, prefix string) int {
+	n := 0
+	for key := range metadata {
+		rest, ok := strings.CutPrefix(key, prefix+".")
+		if !ok {
+			continue
+		}
+		if i, err := strconv.Atoi(rest); err == nil && i >= 0 && i+1 > n {
+			n = i + 1
+		}
+	}
+	return n
+}
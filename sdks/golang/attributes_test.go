@@ -0,0 +1,119 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay_test
+
+import (
+	"context"
+	"testing"
+
+	agentreplay "github.com/sushanthpy/agentreplay/sdks/golang"
+	"github.com/sushanthpy/agentreplay/sdks/golang/agentreplaytest"
+)
+
+func TestAddAttributesMerge(t *testing.T) {
+	tests := []struct {
+		name   string
+		update agentreplay.AttributeUpdate
+		want   map[string]string
+	}{
+		{
+			name:   "scalar replaces",
+			update: agentreplay.AttributeUpdate{Attributes: map[string]string{"review": "done"}},
+			want:   map[string]string{"review": "done", "tag.0": "a", "tag.1": "b"},
+		},
+		{
+			name:   "list appends",
+			update: agentreplay.AttributeUpdate{Lists: map[string][]string{"tag": {"c"}}},
+			want:   map[string]string{"review": "pending", "tag.0": "a", "tag.1": "b", "tag.2": "c"},
+		},
+		{
+			name:   "new list starts at zero",
+			update: agentreplay.AttributeUpdate{Lists: map[string][]string{"label": {"x", "y"}}},
+			want:   map[string]string{"tag.0": "a", "tag.1": "b", "label.0": "x", "label.1": "y"},
+		},
+		{
+			name: "replace list",
+			update: agentreplay.AttributeUpdate{
+				Lists:        map[string][]string{"tag": {"z"}},
+				ReplaceLists: []string{"tag"},
+			},
+			want: map[string]string{"tag.0": "z", "tag.1": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := agentreplaytest.NewMockServer()
+			defer mock.Close()
+			c := agentreplay.NewClient(mock.URL(), 1)
+			defer c.Close()
+			ctx := context.Background()
+
+			result, err := c.CreateTrace(ctx, agentreplay.CreateTraceOptions{
+				Tags:     []string{"a", "b"},
+				Metadata: map[string]interface{}{"name": "span"},
+			})
+			if err != nil {
+				t.Fatalf("CreateTrace: %v", err)
+			}
+			if err := c.UpdateSpan(ctx, result.EdgeID, map[string]string{"review": "pending"}); err != nil {
+				t.Fatalf("UpdateSpan: %v", err)
+			}
+			if err := c.AddAttributes(ctx, result.EdgeID, tt.update); err != nil {
+				t.Fatalf("AddAttributes: %v", err)
+			}
+
+			attrs := mock.Spans()[0].Attributes
+			for key, want := range tt.want {
+				if got, ok := attrs[key]; !ok || got != want {
+					t.Errorf("attribute %s = %q (set: %v), want %q", key, got, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAddTagsKeepsExistingTags(t *testing.T) {
+	mock := agentreplaytest.NewMockServer()
+	defer mock.Close()
+	c := agentreplay.NewClient(mock.URL(), 1)
+	defer c.Close()
+	ctx := context.Background()
+
+	result, err := c.CreateTrace(ctx, agentreplay.CreateTraceOptions{Tags: []string{"early"}})
+	if err != nil {
+		t.Fatalf("CreateTrace: %v", err)
+	}
+	if err := c.AddTags(ctx, result.EdgeID, "late", "later"); err != nil {
+		t.Fatalf("AddTags: %v", err)
+	}
+
+	attrs := mock.Spans()[0].Attributes
+	for key, want := range map[string]string{"tag.0": "early", "tag.1": "late", "tag.2": "later"} {
+		if got := attrs[key]; got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestAddAttributesRejectsReserved(t *testing.T) {
+	c := agentreplay.NewClient("http://127.0.0.1:0", 1)
+	defer c.Close()
+	err := c.AddAttributes(context.Background(), "1", agentreplay.AttributeUpdate{
+		Attributes: map[string]string{"session_id": "2"},
+	})
+	if err == nil {
+		t.Fatal("AddAttributes accepted a reserved attribute")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "context"
+
+// WithBackpressureThreshold sets the high-water mark of the WithAsyncBuffer
+// queue: once n or more spans are waiting to be sent, EnqueueSpan and
+// Backpressure report backpressure so producers can slow down before the
+// queue fills and spans are dropped. It defaults to 80% of the queue's
+// capacity of ten batches. Without WithAsyncBuffer it has no effect.
+func WithBackpressureThreshold(n int) ClientOption {
+	return func(c *Client) {
+		c.highWaterMark = n
+	}
+}
+
+// Backpressure reports whether the async buffer's queue is at or above its
+// high-water mark (see WithBackpressureThreshold). It is a cooperative
+// signal: spans are still accepted until the queue is full. It is always
+// false without WithAsyncBuffer.
+func (c *Client) Backpressure() bool {
+	return c.buffer != nil && c.buffer.backpressure()
+}
+
+// EnqueueSpan queues a prepared span for sending, like a single-span
+// IngestBatch, and reports whether the queue is under backpressure
+// afterwards. Callers that see true should throttle their own work until
+// Backpressure clears. The span is validated first unless
+// WithSkipValidation is set.
+//
+// With WithAsyncBuffer the span is queued and sent in the background;
+// otherwise it is delivered as the Create* methods deliver spans, and
+// backpressure is never reported. Sampling does not apply.
+func (c *Client) EnqueueSpan(ctx context.Context, span SpanInput) (bool, error) {
+	if !c.skipValidation {
+		if err := span.Validate(); err != nil {
+			return false, err
+		}
+	}
+	if err := c.dispatch(ctx, c.tenantID, span); err != nil {
+		return false, err
+	}
+	return c.Backpressure(), nil
+}
+
+// backpressureFraction is the default high-water mark as a fraction of the
+// queue's capacity.
+const backpressureFraction = 0.8
+
+// queueHighWater returns the queue length at which backpressure starts for a
+// queue of the given capacity.
+func queueHighWater(threshold, capacity int) int {
+	if threshold > 0 {
+		return threshold
+	}
+	if n := int(float64(capacity) * backpressureFraction); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// backpressure reports whether the queue is at or above its high-water mark.
+func (b *asyncBuffer) backpressure() bool {
+	return len(b.queue) >= b.highWater
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnqueueSpanBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"accepted":1}`))
+	}))
+	defer srv.Close()
+
+	// One span per batch, so the first send blocks the flusher and every
+	// later span waits in the queue.
+	c := NewClient(srv.URL, 1, WithAsyncBuffer(1, time.Hour), WithBackpressureThreshold(3))
+	defer c.Close()
+	ctx := context.Background()
+
+	spans := testSpans(5)
+	pressure, err := c.EnqueueSpan(ctx, spans[0])
+	if err != nil {
+		t.Fatalf("EnqueueSpan: %v", err)
+	}
+	if pressure {
+		t.Fatal("EnqueueSpan reported backpressure for an empty queue")
+	}
+	// Wait for the flusher to take the first span and block on it.
+	for deadline := time.Now().Add(time.Second); len(c.buffer.queue) > 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("flusher never picked up the first span")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	want := []bool{false, false, true, true}
+	for i, span := range spans[1:] {
+		pressure, err := c.EnqueueSpan(ctx, span)
+		if err != nil {
+			t.Fatalf("EnqueueSpan %d: %v", i+1, err)
+		}
+		if pressure != want[i] {
+			t.Errorf("EnqueueSpan %d with %d queued reported backpressure %v, want %v", i+1, i+1, pressure, want[i])
+		}
+	}
+	if !c.Backpressure() {
+		t.Error("Backpressure() = false with the queue above the threshold")
+	}
+
+	close(release)
+	if err := c.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if c.Backpressure() {
+		t.Error("Backpressure() = true after Flush")
+	}
+	if dropped := c.DroppedSpans(); dropped != 0 {
+		t.Errorf("DroppedSpans() = %d, want 0", dropped)
+	}
+}
+
+func TestEnqueueSpanWithoutBuffer(t *testing.T) {
+	srv, requests := newIngestServer(t)
+	c := NewClient(srv.URL, 1, WithBackpressureThreshold(1))
+	defer c.Close()
+
+	span := testSpans(1)[0]
+	pressure, err := c.EnqueueSpan(context.Background(), span)
+	if err != nil {
+		t.Fatalf("EnqueueSpan: %v", err)
+	}
+	if pressure {
+		t.Error("EnqueueSpan reported backpressure without WithAsyncBuffer")
+	}
+	if got := <-requests; len(got.spans) != 1 || got.spans[0].SpanID != span.SpanID {
+		t.Errorf("server received %+v, want span %s", got.spans, span.SpanID)
+	}
+}
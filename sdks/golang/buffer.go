@@ -0,0 +1,248 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// asyncQueueBatches is how many full batches the async queue holds before
+// new spans are dropped.
+const asyncQueueBatches = 10
+
+// defaultFlushInterval is used when WithAsyncBuffer is given a non-positive
+// flush interval.
+const defaultFlushInterval = time.Second
+
+// WithAsyncBuffer makes the Create* methods return as soon as their span is
+// queued in memory, instead of waiting for the HTTP round-trip. Their results
+// still carry the generated EdgeID, so children can be parented as usual.
+//
+// Queued spans are sent in batches, parents first, whenever maxBatch spans
+// are pending or flushInterval elapses. The queue holds ten batches; spans
+// that arrive while it is full, or whose batch fails to send, are dropped
+// and counted by DroppedSpans. Flush sends everything queued so far and
+// waits for it; Close does the same before shutting the buffer down.
+func WithAsyncBuffer(maxBatch int, flushInterval time.Duration) ClientOption {
+	return func(c *Client) {
+		if maxBatch < 1 {
+			maxBatch = 1
+		}
+		if flushInterval <= 0 {
+			flushInterval = defaultFlushInterval
+		}
+		c.buffer = &asyncBuffer{maxBatch: maxBatch, flushInterval: flushInterval}
+	}
+}
+
+// DroppedSpans returns how many spans the async buffer has dropped, either
+// because the queue was full or because their batch failed to send. It is
+// always zero without WithAsyncBuffer.
+func (c *Client) DroppedSpans() uint64 {
+	if c.buffer == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&c.buffer.dropped)
+}
+
+// bufferedSpan is a queued span together with the tenant it belongs to.
+type bufferedSpan struct {
+	tenantID int64
+	span     SpanInput
+}
+
+// asyncBuffer queues spans for a background goroutine that sends them in
+// batches.
+type asyncBuffer struct {
+	maxBatch      int
+	flushInterval time.Duration
+	highWater     int
+
+	queue   chan bufferedSpan
+	flushes chan chan error
+	stop    chan struct{}
+	wg      sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+
+	// closeCtx bounds the final drain and closeErr reports it; both are
+	// handed to run through the stop channel.
+	closeCtx context.Context
+	closeErr error
+
+	dropped uint64
+	logger  *slog.Logger
+	metrics Metrics
+}
+
+// start launches the background flush goroutine.
+func (b *asyncBuffer) start(c *Client) {
+	b.logger = c.logger
+	b.metrics = c.metrics
+	b.queue = make(chan bufferedSpan, b.maxBatch*asyncQueueBatches)
+	b.highWater = queueHighWater(c.highWaterMark, cap(b.queue))
+	b.flushes = make(chan chan error)
+	b.stop = make(chan struct{})
+	b.wg.Add(1)
+	go b.run(c)
+}
+
+// enqueue queues a span without blocking. It returns false once the buffer
+// is closed, in which case the caller should send the span itself.
+func (b *asyncBuffer) enqueue(tenantID int64, span SpanInput) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return false
+	}
+	select {
+	case b.queue <- bufferedSpan{tenantID: tenantID, span: span}:
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+		b.metrics.IncDropped(1)
+		b.logger.Warn("async buffer full, dropping span", "span_id", span.SpanID)
+	}
+	return true
+}
+
+// flush sends every span queued before the call and waits for the sends to
+// finish or ctx to expire. It returns the send errors, if any.
+func (b *asyncBuffer) flush(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case b.flushes <- done:
+	case <-b.stop:
+		// Closing drains the queue itself.
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops accepting spans and waits for the queue to drain, sending
+// with ctx. It returns the drain's send errors, or ctx's error if ctx ends
+// first; in that case the remaining sends fail fast and are dropped.
+func (b *asyncBuffer) close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.closeCtx = ctx
+	b.mu.Unlock()
+
+	close(b.stop)
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return b.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run collects queued spans and sends them when a batch fills, the flush
+// interval elapses, or the buffer is closed.
+func (b *asyncBuffer) run(c *Client) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]bufferedSpan, 0, b.maxBatch)
+	flush := func(ctx context.Context) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := b.send(ctx, c, batch)
+		batch = batch[:0]
+		return err
+	}
+	// drain sends everything currently queued.
+	drain := func(ctx context.Context) error {
+		var errs []error
+		for {
+			select {
+			case item := <-b.queue:
+				batch = append(batch, item)
+				if len(batch) >= b.maxBatch {
+					errs = append(errs, flush(ctx))
+				}
+			default:
+				errs = append(errs, flush(ctx))
+				return errors.Join(errs...)
+			}
+		}
+	}
+
+	for {
+		select {
+		case item := <-b.queue:
+			batch = append(batch, item)
+			if len(batch) >= b.maxBatch {
+				flush(context.Background())
+			}
+		case <-ticker.C:
+			flush(context.Background())
+		case done := <-b.flushes:
+			done <- drain(context.Background())
+		case <-b.stop:
+			b.closeErr = drain(b.closeCtx)
+			return
+		}
+	}
+}
+
+// send ingests a batch, one request per tenant, counting failed spans as
+// dropped.
+func (b *asyncBuffer) send(ctx context.Context, c *Client, batch []bufferedSpan) error {
+	var tenants []int64
+	byTenant := make(map[int64][]SpanInput)
+	for _, item := range batch {
+		if _, ok := byTenant[item.tenantID]; !ok {
+			tenants = append(tenants, item.tenantID)
+		}
+		byTenant[item.tenantID] = append(byTenant[item.tenantID], item.span)
+	}
+
+	var errs []error
+	for _, tenantID := range tenants {
+		spans := latestRevisions(byTenant[tenantID])
+		if _, err := c.sendSpans(ctx, tenantID, orderParentsFirst(spans)); err != nil {
+			atomic.AddUint64(&b.dropped, uint64(len(spans)))
+			b.logger.Error("failed to send buffered spans, dropping them", "spans", len(spans), errorAttr(err))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
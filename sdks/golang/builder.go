@@ -0,0 +1,168 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// TraceBuilder collects the spans of a session locally and sends them in a
+// single IngestBatch on Commit, instead of one request per Create* call.
+// Each span is added under a logical name, and a ParentID naming an earlier
+// span in the builder is resolved to that span's EdgeID, so a whole tree can
+// be described before anything is sent.
+//
+// Add methods record the first error they hit and skip later spans; Commit
+// reports it. Sampling, name sanitizing and validation apply as they do for
+// the Create* methods, but WithAsyncBuffer and WithFireAndForget do not:
+// Commit always sends synchronously, under the client's tenant. It is safe
+// for concurrent use.
+//
+// Example:
+//
+//	b := client.NewTraceBuilder(sessionID)
+//	b.AddSpan("root", agentreplay.CreateTraceOptions{SpanType: agentreplay.SpanTypeRoot})
+//	b.AddGenAI("llm", agentreplay.CreateGenAITraceOptions{ParentID: "root", Model: "gpt-4o"})
+//	b.AddTool("search", agentreplay.CreateToolTraceOptions{ParentID: "llm", ToolName: "web_search"})
+//	ids, err := b.Commit(ctx)
+type TraceBuilder struct {
+	client    *Client
+	sessionID int64
+
+	mu    sync.Mutex
+	spans []SpanInput
+	ids   map[string]string
+	err   error
+}
+
+// NewTraceBuilder starts a TraceBuilder for sessionID. Spans whose options
+// leave SessionID zero join it.
+func (c *Client) NewTraceBuilder(sessionID int64) *TraceBuilder {
+	return &TraceBuilder{
+		client:    c,
+		sessionID: sessionID,
+		ids:       make(map[string]string),
+	}
+}
+
+// traceBuilderKey is the context key under which a TraceBuilder captures the
+// spans emitted by the Create* methods it calls.
+type traceBuilderKey struct{}
+
+// capture records a span emitted on the builder's behalf. It is called by
+// emitSpan in place of sending.
+func (b *TraceBuilder) capture(span SpanInput) {
+	b.spans = append(b.spans, span)
+}
+
+// add runs create with a context that captures its span, registering the
+// resulting EdgeID under name. It returns the EdgeID, or "" after an error.
+func (b *TraceBuilder) add(name string, parentID *string, sessionID *int64, create func(context.Context) (string, error)) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return ""
+	}
+	if _, ok := b.ids[name]; ok {
+		b.err = fmt.Errorf("duplicate span name %q", name)
+		return ""
+	}
+	if id, ok := b.ids[*parentID]; ok {
+		*parentID = id
+	}
+	if *sessionID == 0 {
+		*sessionID = b.sessionID
+	}
+
+	edgeID, err := create(context.WithValue(context.Background(), traceBuilderKey{}, b))
+	if err != nil {
+		b.err = fmt.Errorf("span %q: %w", name, err)
+		return ""
+	}
+	b.ids[name] = edgeID
+	return edgeID
+}
+
+// AddSpan adds a span built as by CreateTrace and returns its EdgeID.
+func (b *TraceBuilder) AddSpan(name string, opts CreateTraceOptions) string {
+	return b.add(name, &opts.ParentID, &opts.SessionID, func(ctx context.Context) (string, error) {
+		result, err := b.client.CreateTrace(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		return result.EdgeID, nil
+	})
+}
+
+// AddGenAI adds a span built as by CreateGenAITrace and returns its EdgeID.
+func (b *TraceBuilder) AddGenAI(name string, opts CreateGenAITraceOptions) string {
+	return b.add(name, &opts.ParentID, &opts.SessionID, func(ctx context.Context) (string, error) {
+		result, err := b.client.CreateGenAITrace(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		return result.EdgeID, nil
+	})
+}
+
+// AddTool adds a span built as by CreateToolTrace and returns its EdgeID.
+func (b *TraceBuilder) AddTool(name string, opts CreateToolTraceOptions) string {
+	return b.add(name, &opts.ParentID, &opts.SessionID, func(ctx context.Context) (string, error) {
+		result, err := b.client.CreateToolTrace(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		return result.EdgeID, nil
+	})
+}
+
+// AddRetrieval adds a span built as by CreateRetrievalTrace and returns its
+// EdgeID.
+func (b *TraceBuilder) AddRetrieval(name string, opts CreateRetrievalTraceOptions) string {
+	return b.add(name, &opts.ParentID, &opts.SessionID, func(ctx context.Context) (string, error) {
+		result, err := b.client.CreateRetrievalTrace(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		return result.EdgeID, nil
+	})
+}
+
+// Commit sends the spans added since the last Commit in one IngestBatch,
+// parents first, and returns the EdgeID of every span added to the builder
+// keyed by its logical name. If an Add method failed, nothing is sent and
+// its error is returned.
+func (b *TraceBuilder) Commit(ctx context.Context) (map[string]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if len(b.spans) > 0 {
+		if _, err := b.client.IngestBatch(ctx, b.spans); err != nil {
+			return nil, err
+		}
+		b.spans = nil
+	}
+
+	ids := make(map[string]string, len(b.ids))
+	for name, id := range b.ids {
+		ids[name] = id
+	}
+	return ids, nil
+}
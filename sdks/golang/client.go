@@ -17,27 +17,91 @@ package agentreplay
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // Client is the Agentreplay client for Go applications.
 type Client struct {
-	url            string
-	tenantID       int64
-	projectID      int64
-	agentID        int64
-	timeout        time.Duration
-	httpClient     *http.Client
-	sessionCounter int64
+	url        string
+	tenantID   int64
+	projectID  int64
+	agentID    int64
+	timeout    time.Duration
+	httpClient *http.Client
+
+	customHTTPClient bool
+	transportConfig  TransportConfig
+	maxRedirects     int
+	maxBatchSize     int
+	skipValidation   bool
+	minDeadline      time.Duration
+	retryAttempts    int
+	retryBaseDelay   time.Duration
+	idempotency      bool
+	limiter          *rateLimiter
+	nameSanitizer    func(string) string
+	maxNameLength    int
+	buffer           *asyncBuffer
+	highWaterMark    int
+	sourceLocation   bool
+	autoName         bool
+	redactor         func(attrKey, attrValue string) string
+	costTable        CostTable
+	tokenizer        Tokenizer
+	headers          http.Header
+	headerFunc       func(ctx context.Context) http.Header
+	apiKey           string
+	basicAuth        bool
+	basicAuthUser    string
+	basicAuthPass    string
+
+	compression          Compression
+	compressionThreshold int
+
+	logger  *slog.Logger
+	metrics Metrics
+
+	sampler           Sampler
+	sessionSamples    *sessionSampleCache
+	sampleRates       map[SpanType]float64
+	defaultSampleRate float64
+	synthetic         bool
+	environment       Environment
+	customEnv         bool
+
+	protobuf          bool
+	protobufMu        sync.Mutex
+	protobufChecked   bool
+	protobufSupported bool
+
+	minVersion     string
+	versionMu      sync.Mutex
+	versionChecked bool
+	versionErr     error
+	serverVersion  atomic.Pointer[string]
+
+	rollup         *rollupTracker
+	fireAndForget  bool
+	inflight       sync.WaitGroup
+	spanProcessors []func(*SpanInput) bool
+	exporter       Exporter
+
+	sharedHTTPClient bool
+	sharedExporter   bool
 }
 
 // ClientOption is a function that configures a Client.
@@ -57,18 +121,93 @@ func WithAgentID(agentID int64) ClientOption {
 	}
 }
 
-// WithTimeout sets the request timeout.
+// WithTimeout sets how long each request attempt may take (default 30s). It
+// is enforced through the request context, so it also applies with
+// WithHTTPClient, and a shorter deadline on the caller's context always
+// wins. Zero disables the client-level timeout, leaving requests bounded
+// only by their context.
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *Client) {
 		c.timeout = timeout
-		c.httpClient.Timeout = timeout
 	}
 }
 
-// WithHTTPClient sets a custom HTTP client.
+// WithMinDeadline makes requests fail fast with ErrDeadlineTooShort when the
+// context deadline is closer than d, instead of issuing a request that is
+// almost certain to time out. This usually means a parent deadline has
+// nearly expired. Disabled by default.
+func WithMinDeadline(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.minDeadline = d
+	}
+}
+
+// WithSyntheticTraffic marks every span sent by the client with
+// synthetic=true, so load-test and CI traffic can be excluded from product
+// analytics while remaining queryable (see QueryFilter.Synthetic).
+func WithSyntheticTraffic(synthetic bool) ClientOption {
+	return func(c *Client) {
+		c.synthetic = synthetic
+	}
+}
+
+// WithEnvironment stamps environment=env on every span sent by the client,
+// from the Create* methods and IngestBatch alike, so traces can be split by
+// deployment at write time (see QueryFilter.Environment). Spans that already
+// carry an environment attribute keep it.
+//
+// env must be one of the Environment constants unless
+// WithCustomEnvironments is also given; otherwise it is ignored and a
+// warning is logged.
+func WithEnvironment(env Environment) ClientOption {
+	return func(c *Client) {
+		c.environment = env
+	}
+}
+
+// WithCustomEnvironments lets WithEnvironment accept environment names
+// beyond the predefined constants, such as "qa" or "eu-prod".
+func WithCustomEnvironments() ClientOption {
+	return func(c *Client) {
+		c.customEnv = true
+	}
+}
+
+// WithSpanProcessor registers a last-chance hook run on every span just
+// before it is sent, from the Create* methods and IngestBatch alike. The hook
+// may mutate the span, for example to strip or enrich attributes; returning
+// false drops it. Processors run in registration order on a copy of the
+// caller's span slice.
+func WithSpanProcessor(processor func(*SpanInput) bool) ClientOption {
+	return func(c *Client) {
+		c.spanProcessors = append(c.spanProcessors, processor)
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client. The client is used as-is: its
+// redirect policy and transport are left untouched, and WithTransportConfig
+// is ignored.
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(c *Client) {
 		c.httpClient = httpClient
+		c.customHTTPClient = true
+	}
+}
+
+// WithMaxBatchSize splits IngestBatch calls larger than n spans into
+// several requests of at most n spans, to stay under server body limits.
+// Zero, the default, sends each batch in one request.
+func WithMaxBatchSize(n int) ClientOption {
+	return func(c *Client) {
+		c.maxBatchSize = n
+	}
+}
+
+// WithMaxRedirects sets how many redirects the built-in HTTP client follows
+// before failing. Defaults to 10; zero disables following redirects.
+func WithMaxRedirects(n int) ClientOption {
+	return func(c *Client) {
+		c.maxRedirects = n
 	}
 }
 
@@ -84,28 +223,87 @@ func WithHTTPClient(httpClient *http.Client) ClientOption {
 //	)
 func NewClient(baseURL string, tenantID int64, opts ...ClientOption) *Client {
 	c := &Client{
-		url:       strings.TrimSuffix(baseURL, "/"),
-		tenantID:  tenantID,
-		projectID: 0,
-		agentID:   1,
-		timeout:   30 * time.Second,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 50,
-				IdleConnTimeout:     30 * time.Second,
-			},
-		},
+		url:                  strings.TrimSuffix(baseURL, "/"),
+		tenantID:             tenantID,
+		projectID:            0,
+		agentID:              1,
+		timeout:              30 * time.Second,
+		httpClient:           &http.Client{},
+		maxRedirects:         10,
+		defaultSampleRate:    1,
+		maxNameLength:        defaultMaxNameLength,
+		costTable:            DefaultCostTable(),
+		tokenizer:            HeuristicTokenizer(),
+		compressionThreshold: defaultCompressionThreshold,
+		logger:               discardLogger,
+		metrics:              noopMetrics{},
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	if !c.customHTTPClient {
+		c.httpClient.Transport = c.transportConfig.transport()
+		c.httpClient.CheckRedirect = c.checkRedirect
+	}
+
+	c.setup()
 	return c
 }
 
+// setup validates the configuration and starts the client's background
+// machinery once all options have run.
+func (c *Client) setup() {
+	if c.environment != "" && !c.customEnv && !c.environment.Known() {
+		c.logger.Warn("ignoring unknown environment; use WithCustomEnvironments to allow it", "environment", c.environment)
+		c.environment = ""
+	}
+
+	if c.sampler != nil {
+		c.sessionSamples = newSessionSampleCache(sessionSampleCacheSize)
+	}
+
+	if c.exporter == nil {
+		c.exporter = &httpExporter{client: c}
+	}
+
+	if c.buffer != nil {
+		c.buffer.start(c)
+	}
+}
+
+// TenantID returns the client's tenant ID.
+func (c *Client) TenantID() int64 {
+	return c.tenantID
+}
+
+// ProjectID returns the client's default project ID.
+func (c *Client) ProjectID() int64 {
+	return c.projectID
+}
+
+// AgentID returns the client's default agent ID.
+func (c *Client) AgentID() int64 {
+	return c.agentID
+}
+
+// checkRedirect re-attaches the original request headers to each redirect.
+// Go's client drops Authorization on cross-host redirects, which breaks
+// servers behind load balancers that redirect to a canonical host. Headers
+// are only re-attached when the redirect didn't already carry them.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > c.maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", c.maxRedirects)
+	}
+	for key, values := range via[0].Header {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = values
+		}
+	}
+	return nil
+}
+
 // generateEdgeID generates a unique edge ID.
 func generateEdgeID() string {
 	timestamp := time.Now().UnixMilli()
@@ -114,13 +312,333 @@ func generateEdgeID() string {
 	return fmt.Sprintf("%x", edgeID)
 }
 
-// nextSessionID returns the next session ID.
+// detectSystem guesses the gen_ai.system of a model from its name.
+func detectSystem(model string) string {
+	modelLower := strings.ToLower(model)
+	switch {
+	case strings.Contains(modelLower, "gpt") || strings.Contains(modelLower, "openai"):
+		return "openai"
+	case strings.Contains(modelLower, "claude") || strings.Contains(modelLower, "anthropic"):
+		return "anthropic"
+	case strings.Contains(modelLower, "llama") || strings.Contains(modelLower, "meta"):
+		return "meta"
+	case strings.Contains(modelLower, "gemini") || strings.Contains(modelLower, "palm"):
+		return "google"
+	default:
+		return "unknown"
+	}
+}
+
+// applyMetadata copies caller metadata into attributes under a metadata.
+// prefix, skipping keys that collide with attributes already set.
+func applyMetadata(attributes map[string]string, metadata map[string]interface{}) {
+	for k, v := range metadata {
+		if _, exists := attributes[k]; exists {
+			continue
+		}
+		switch val := v.(type) {
+		case string:
+			attributes["metadata."+k] = val
+		default:
+			attributes["metadata."+k] = toJSON(v)
+		}
+	}
+}
+
+// applyTags writes tags as indexed tag.N attributes so each one is
+// queryable on its own instead of being JSON-blobbed together.
+func applyTags(attributes map[string]string, tags []string) {
+	for i, tag := range tags {
+		attributes["tag."+strconv.Itoa(i)] = tag
+	}
+}
+
+// applyTagFilter forwards the filter's tags as a comma-separated tag param.
+// Traces match when they carry any of the tags, or all of them when
+// TagsMatchAll is set.
+func applyTagFilter(params map[string]string, filter *QueryFilter) {
+	if len(filter.Tags) == 0 {
+		return
+	}
+	params["tag"] = strings.Join(filter.Tags, ",")
+	if filter.TagsMatchAll {
+		params["tag_match"] = "all"
+	} else {
+		params["tag_match"] = "any"
+	}
+}
+
+// applyFeedbackFilter forwards the filter's feedback constraints. A nil
+// HasFeedback leaves feedback presence unconstrained.
+func applyFeedbackFilter(params map[string]string, filter *QueryFilter) {
+	if filter.HasFeedback != nil {
+		params["has_feedback"] = strconv.FormatBool(*filter.HasFeedback)
+	}
+	if filter.MinFeedback != nil {
+		params["min_feedback"] = strconv.Itoa(*filter.MinFeedback)
+	}
+	if filter.MaxFeedback != nil {
+		params["max_feedback"] = strconv.Itoa(*filter.MaxFeedback)
+	}
+}
+
+// applyDurationFilter forwards the filter's duration bounds, in microseconds,
+// so the server filters on duration_us.
+func applyDurationFilter(params map[string]string, filter *QueryFilter) {
+	if filter.MinDurationUs != nil {
+		params["min_duration_us"] = strconv.FormatInt(*filter.MinDurationUs, 10)
+	}
+	if filter.MaxDurationUs != nil {
+		params["max_duration_us"] = strconv.FormatInt(*filter.MaxDurationUs, 10)
+	}
+}
+
+// applySpanTypeFilter forwards the filter's span types: SpanType as
+// span_type and SpanTypes as a comma-separated span_types list matching
+// any of them.
+func applySpanTypeFilter(params map[string]string, filter *QueryFilter) {
+	if filter.SpanType != nil {
+		params["span_type"] = strconv.Itoa(int(*filter.SpanType))
+	}
+	if len(filter.SpanTypes) > 0 {
+		types := make([]string, len(filter.SpanTypes))
+		for i, t := range filter.SpanTypes {
+			types[i] = strconv.Itoa(int(t))
+		}
+		params["span_types"] = strings.Join(types, ",")
+	}
+}
+
+// applyAttributeFilter forwards the filter's attribute constraints as one
+// attr.<key>=<value> param each. The server ANDs them: a span matches only
+// if every listed attribute has exactly the given value.
+func applyAttributeFilter(params map[string]string, filter *QueryFilter) {
+	for k, v := range filter.Attributes {
+		params["attr."+k] = v
+	}
+}
+
+// applyConfidenceFilter forwards the filter's confidence bounds.
+func applyConfidenceFilter(params map[string]string, filter *QueryFilter) {
+	if filter.MinConfidence != nil {
+		params["min_confidence"] = strconv.FormatFloat(*filter.MinConfidence, 'f', -1, 64)
+	}
+	if filter.MaxConfidence != nil {
+		params["max_confidence"] = strconv.FormatFloat(*filter.MaxConfidence, 'f', -1, 64)
+	}
+}
+
+// sessionCounter backs auto-generated session IDs. It is shared by every
+// Client in the process, clones included, so that separate clients never
+// hand out the same ID.
+var sessionCounter int64
+
+// spanLineage resolves a new span's session and parent. Explicit values win;
+// otherwise the span set on ctx by ContextWithSpan is used, and a fresh
+// session ID is allocated as a last resort.
+func (c *Client) spanLineage(ctx context.Context, sessionID int64, parentID string) (int64, string) {
+	if ctxParent, ctxSession, ok := SpanFromContext(ctx); ok {
+		if parentID == "" {
+			parentID = ctxParent
+		}
+		if sessionID == 0 {
+			sessionID = ctxSession
+		}
+	}
+	if sessionID == 0 {
+		sessionID = c.nextSessionID()
+	}
+	return sessionID, parentID
+}
+
+// nextSessionID returns the next auto-generated session ID. It is only used
+// when a call leaves SessionID at zero; an explicit SessionID always bypasses
+// the counter.
 func (c *Client) nextSessionID() int64 {
-	return atomic.AddInt64(&c.sessionCounter, 1)
+	return atomic.AddInt64(&sessionCounter, 1)
+}
+
+// tenantOverrideKey is the context key for a per-call tenant ID that
+// replaces the client default in the X-Tenant-ID header.
+type tenantOverrideKey struct{}
+
+// scopeIDs resolves per-call tenant, project and agent overrides against the
+// client defaults. Zero means "use the client default", so every Create*
+// method falls back to the same configured agent ID as UpdateTrace.
+func (c *Client) scopeIDs(tenantID, projectID, agentID int64) (int64, int64, int64) {
+	if tenantID == 0 {
+		tenantID = c.tenantID
+	}
+	if projectID == 0 {
+		projectID = c.projectID
+	}
+	if agentID == 0 {
+		agentID = c.agentID
+	}
+	return tenantID, projectID, agentID
+}
+
+// emitSpan sends, or queues with WithAsyncBuffer, a single span created by one
+// of the Create* methods after sanitizing its name, unless the samplers drop
+// it based on its session or spanType.
+func (c *Client) emitSpan(ctx context.Context, tenantID, sessionID int64, spanType SpanType, span SpanInput) error {
+	if !c.sampleSession(sessionID) || !c.sampleSpanType(spanType) {
+		return nil
+	}
+	span.Name = c.sanitizeName(span.Name)
+	adoptTraceID(ctx, &span)
+	if !c.skipValidation {
+		if err := span.Validate(); err != nil {
+			return c.degrade(ctx, span, err)
+		}
+	}
+	if c.sourceLocation {
+		applySourceLocation(span.Attributes)
+	}
+	if b, ok := ctx.Value(traceBuilderKey{}).(*TraceBuilder); ok {
+		b.capture(span)
+		return nil
+	}
+	// Roots are tracked before they are handed off, so a fire-and-forget
+	// send of the original root is ordered with its later updates.
+	isRoot := span.ParentSpanID == nil
+	if c.rollup != nil && isRoot {
+		c.rollupSpan(ctx, tenantID, span)
+	}
+	if err := c.dispatch(ctx, tenantID, span); err != nil {
+		return err
+	}
+	if c.rollup != nil && !isRoot {
+		c.rollupSpan(ctx, tenantID, span)
+	}
+	return nil
 }
 
-// request makes an HTTP request to the Agentreplay server.
+// dispatch queues or sends a prepared span according to the client's
+// delivery mode.
+func (c *Client) dispatch(ctx context.Context, tenantID int64, span SpanInput) error {
+	if c.buffer != nil && c.buffer.enqueue(tenantID, span) {
+		return nil
+	}
+	if c.fireAndForget {
+		c.sendInBackground(ctx, tenantID, span)
+		return nil
+	}
+	_, err := c.sendSpans(ctx, tenantID, []SpanInput{span})
+	return err
+}
+
+// sendSpans hands spans to the exporter on behalf of tenantID, which posts
+// them to the ingestion endpoint unless WithExporter is set. It returns a
+// nil body without sending when span processors drop every span.
+func (c *Client) sendSpans(ctx context.Context, tenantID int64, spans []SpanInput) ([]byte, error) {
+	if tenantID != c.tenantID {
+		ctx = context.WithValue(ctx, tenantOverrideKey{}, tenantID)
+	}
+	if c.synthetic {
+		spans = withAttribute(spans, "synthetic", "true")
+	}
+	if c.environment != "" {
+		spans = withDefaultAttribute(spans, "environment", string(c.environment))
+	}
+	kept := c.processSpans(spans)
+	if dropped := len(spans) - len(kept); dropped > 0 {
+		c.metrics.IncDropped(dropped)
+	}
+	spans = kept
+	if len(spans) == 0 {
+		return nil, nil
+	}
+	if c.redactor != nil {
+		spans = c.redactSpans(spans)
+	}
+	respBody, err := c.export(ctx, spans)
+	if err != nil {
+		c.metrics.IncFailed(len(spans))
+		return nil, err
+	}
+	c.metrics.IncSent(len(spans))
+	return respBody, nil
+}
+
+// processSpans runs the registered span processors, returning the spans
+// they kept.
+func (c *Client) processSpans(spans []SpanInput) []SpanInput {
+	if len(c.spanProcessors) == 0 {
+		return spans
+	}
+	kept := make([]SpanInput, 0, len(spans))
+	for _, span := range spans {
+		keep := true
+		for _, processor := range c.spanProcessors {
+			if !processor(&span) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			kept = append(kept, span)
+		}
+	}
+	return kept
+}
+
+// withAttribute returns a copy of spans with key set on each span's
+// attributes, leaving the caller's spans and maps untouched.
+func withAttribute(spans []SpanInput, key, value string) []SpanInput {
+	out := make([]SpanInput, len(spans))
+	for i, span := range spans {
+		attributes := make(map[string]string, len(span.Attributes)+1)
+		for k, v := range span.Attributes {
+			attributes[k] = v
+		}
+		attributes[key] = value
+		span.Attributes = attributes
+		out[i] = span
+	}
+	return out
+}
+
+// withDefaultAttribute is like withAttribute but leaves spans that already
+// have key untouched.
+func withDefaultAttribute(spans []SpanInput, key, value string) []SpanInput {
+	out := make([]SpanInput, len(spans))
+	for i, span := range spans {
+		if _, ok := span.Attributes[key]; !ok {
+			span = withAttribute([]SpanInput{span}, key, value)[0]
+		}
+		out[i] = span
+	}
+	return out
+}
+
+// request makes a JSON HTTP request to the Agentreplay server.
 func (c *Client) request(ctx context.Context, method, path string, body interface{}, params map[string]string) ([]byte, error) {
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+	return c.doRequest(ctx, method, path, "application/json", bodyBytes, params)
+}
+
+// doRequest sends an already-encoded body to the Agentreplay server.
+func (c *Client) doRequest(ctx context.Context, method, path, contentType string, body []byte, params map[string]string) ([]byte, error) {
+	if c.minDeadline > 0 {
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); remaining < c.minDeadline {
+				return nil, fmt.Errorf("%w: %s left, minimum is %s", ErrDeadlineTooShort, remaining, c.minDeadline)
+			}
+		}
+	}
+
+	if err := c.checkVersion(ctx, path); err != nil {
+		return nil, err
+	}
+
 	reqURL := c.url + path
 
 	if len(params) > 0 {
@@ -131,54 +649,132 @@ func (c *Client) request(ctx context.Context, method, path string, body interfac
 		reqURL += "?" + values.Encode()
 	}
 
-	var bodyReader io.Reader
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+	tenantID := c.tenantID
+	if override, ok := ctx.Value(tenantOverrideKey{}).(int64); ok {
+		tenantID = override
+	}
+
+	body, contentEncoding, err := c.compressBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := c.retryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := c.retryDelay(attempt - 1)
+			c.logger.WarnContext(ctx, "retrying request",
+				"method", method, "path", path, "attempt", attempt, "delay", delay, errorAttr(lastErr))
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, fmt.Errorf("giving up after %d attempts: %w (last error: %v)", attempt-1, err, lastErr)
+			}
+		}
+		if err := c.waitRateLimit(ctx, method, path); err != nil {
+			return nil, err
+		}
+
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		c.applyHeaders(ctx, req)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Tenant-ID", strconv.FormatInt(tenantID, 10))
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
+		if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok {
+			req.Header.Set(idempotencyHeader, key)
+		}
+
+		c.logger.DebugContext(ctx, "sending request", "method", method, "path", path, "attempt", attempt)
+		start := time.Now()
+		status, respBody, err := c.roundTrip(req)
+		c.metrics.ObserveLatency(time.Since(start))
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return nil, lastErr
+			}
+			continue
+		}
+		c.logger.DebugContext(ctx, "request finished",
+			"method", method, "path", path, "status", status, "duration", time.Since(start))
+
+		if status >= 400 {
+			lastErr = &APIError{
+				StatusCode: status,
+				Body:       string(respBody),
+				Endpoint:   method + " " + path,
+			}
+			if retryableStatus(status) {
+				continue
+			}
+			c.logger.WarnContext(ctx, "request rejected", "method", method, "path", path, "status", status)
+			return nil, lastErr
+		}
+
+		return respBody, nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	c.logger.ErrorContext(ctx, "request failed", "method", method, "path", path, "attempts", attempts, errorAttr(lastErr))
+	if attempts > 1 {
+		return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, lastErr)
 	}
+	return nil, lastErr
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", strconv.FormatInt(c.tenantID, 10))
+// roundTrip performs a single request attempt, bounded by the client
+// timeout on top of any deadline already on the request's context, and
+// returns the status code and body.
+func (c *Client) roundTrip(req *http.Request) (int, []byte, error) {
+	if c.timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return 0, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("Agentreplay API error (%d): %s", resp.StatusCode, string(respBody))
+		return 0, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
-
-	return respBody, nil
+	return resp.StatusCode, body, nil
 }
 
 // CreateTrace creates a new trace span.
 func (c *Client) CreateTrace(ctx context.Context, opts CreateTraceOptions) (*TraceResult, error) {
-	edgeID := generateEdgeID()
-	sessionID := opts.SessionID
-	if sessionID == 0 {
-		sessionID = c.nextSessionID()
-	}
-	startTimeUs := nowMicroseconds()
+	return c.createTrace(ctx, generateEdgeID(), opts, nil)
+}
+
+// createTrace implements CreateTrace for a span whose edge ID has already
+// been chosen, as with Span handles, adding extra attributes that have no
+// option of their own.
+func (c *Client) createTrace(ctx context.Context, edgeID string, opts CreateTraceOptions, extra map[string]string) (*TraceResult, error) {
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	startTimeUs, endTimeUs := spanTimes(opts.StartTime, opts.EndTime)
 
 	attributes := map[string]string{
-		"tenant_id":   strconv.FormatInt(c.tenantID, 10),
-		"project_id":  strconv.FormatInt(c.projectID, 10),
-		"agent_id":    strconv.FormatInt(opts.AgentID, 10),
+		"tenant_id":   strconv.FormatInt(tenantID, 10),
+		"project_id":  strconv.FormatInt(projectID, 10),
+		"agent_id":    strconv.FormatInt(agentID, 10),
 		"session_id":  strconv.FormatInt(sessionID, 10),
 		"span_type":   strconv.Itoa(int(opts.SpanType)),
 		"token_count": "0",
@@ -200,16 +796,31 @@ func (c *Client) CreateTrace(ctx context.Context, opts CreateTraceOptions) (*Tra
 		}
 	}
 
-	name := fmt.Sprintf("span_%d", opts.AgentID)
+	name := fmt.Sprintf("span_%d", agentID)
+	if c.autoName {
+		if caller := callerName(); caller != "" {
+			name = caller
+		}
+	}
 	if opts.Metadata != nil {
 		if n, ok := opts.Metadata["name"].(string); ok {
 			name = n
 		}
 	}
 
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
+	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity)
+	for k, v := range extra {
+		attributes[k] = v
+	}
+
 	var parentSpanID *string
-	if opts.ParentID != "" {
-		parentSpanID = &opts.ParentID
+	if parentID != "" {
+		parentSpanID = &parentID
 	}
 
 	span := SpanInput{
@@ -218,19 +829,19 @@ func (c *Client) CreateTrace(ctx context.Context, opts CreateTraceOptions) (*Tra
 		ParentSpanID: parentSpanID,
 		Name:         name,
 		StartTime:    startTimeUs,
-		EndTime:      &startTimeUs,
+		EndTime:      &endTimeUs,
 		Attributes:   attributes,
 	}
 
-	_, err := c.request(ctx, "POST", "/api/v1/traces", map[string]interface{}{"spans": []SpanInput{span}}, nil)
+	err := c.emitSpan(ctx, tenantID, sessionID, opts.SpanType, span)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TraceResult{
 		EdgeID:    edgeID,
-		TenantID:  c.tenantID,
-		AgentID:   opts.AgentID,
+		TenantID:  tenantID,
+		AgentID:   agentID,
 		SessionID: sessionID,
 		SpanType:  opts.SpanType,
 	}, nil
@@ -238,21 +849,24 @@ func (c *Client) CreateTrace(ctx context.Context, opts CreateTraceOptions) (*Tra
 
 // CreateGenAITrace creates a GenAI trace with OpenTelemetry semantic conventions.
 func (c *Client) CreateGenAITrace(ctx context.Context, opts CreateGenAITraceOptions) (*GenAITraceResult, error) {
-	edgeID := generateEdgeID()
-	sessionID := opts.SessionID
-	if sessionID == 0 {
-		sessionID = c.nextSessionID()
-	}
-	startTimeUs := nowMicroseconds()
+	return c.createGenAITrace(ctx, generateEdgeID(), opts, nil)
+}
+
+// createGenAITrace implements CreateGenAITrace for a preset edge ID, adding
+// extra attributes that have no option of their own, as with GenAIStream.
+func (c *Client) createGenAITrace(ctx context.Context, edgeID string, opts CreateGenAITraceOptions, extra map[string]string) (*GenAITraceResult, error) {
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	startTimeUs, endTimeUs := spanTimes(opts.StartTime, opts.EndTime)
 	operationName := opts.OperationName
 	if operationName == "" {
 		operationName = "chat"
 	}
 
 	attributes := map[string]string{
-		"tenant_id":             strconv.FormatInt(c.tenantID, 10),
-		"project_id":            strconv.FormatInt(c.projectID, 10),
-		"agent_id":              strconv.FormatInt(opts.AgentID, 10),
+		"tenant_id":             strconv.FormatInt(tenantID, 10),
+		"project_id":            strconv.FormatInt(projectID, 10),
+		"agent_id":              strconv.FormatInt(agentID, 10),
 		"session_id":            strconv.FormatInt(sessionID, 10),
 		"span_type":             "0",
 		"gen_ai.operation.name": operationName,
@@ -261,82 +875,503 @@ func (c *Client) CreateGenAITrace(ctx context.Context, opts CreateGenAITraceOpti
 	// Auto-detect system from model name
 	system := opts.System
 	if system == "" && opts.Model != "" {
-		modelLower := strings.ToLower(opts.Model)
-		switch {
-		case strings.Contains(modelLower, "gpt") || strings.Contains(modelLower, "openai"):
-			system = "openai"
-		case strings.Contains(modelLower, "claude") || strings.Contains(modelLower, "anthropic"):
-			system = "anthropic"
-		case strings.Contains(modelLower, "llama") || strings.Contains(modelLower, "meta"):
-			system = "meta"
-		case strings.Contains(modelLower, "gemini") || strings.Contains(modelLower, "palm"):
-			system = "google"
-		default:
-			system = "unknown"
-		}
+		system = detectSystem(opts.Model)
+	}
+
+	if system != "" {
+		attributes["gen_ai.system"] = system
+	}
+
+	if opts.Model != "" {
+		attributes["gen_ai.request.model"] = opts.Model
+		attributes["gen_ai.response.model"] = opts.Model
+	}
+
+	// Model parameters
+	if opts.ModelParameters != nil {
+		for k, v := range opts.ModelParameters {
+			attributes["gen_ai.request."+k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	// Token usage, estimated with the client's tokenizer where not reported
+	inputUsage, outputUsage, totalUsage, estimated := c.estimateUsage(opts)
+	if inputUsage != nil {
+		attributes["gen_ai.usage.prompt_tokens"] = strconv.Itoa(*inputUsage)
+		attributes["gen_ai.usage.input_tokens"] = strconv.Itoa(*inputUsage)
+	}
+	if outputUsage != nil {
+		attributes["gen_ai.usage.completion_tokens"] = strconv.Itoa(*outputUsage)
+		attributes["gen_ai.usage.output_tokens"] = strconv.Itoa(*outputUsage)
+	}
+	if totalUsage != nil {
+		attributes["gen_ai.usage.total_tokens"] = strconv.Itoa(*totalUsage)
+		attributes["token_count"] = strconv.Itoa(*totalUsage)
+	}
+	if u := opts.Usage; u != nil {
+		if u.CachedTokens > 0 {
+			attributes["gen_ai.usage.cache_read_tokens"] = strconv.Itoa(u.CachedTokens)
+		}
+		if u.ReasoningTokens > 0 {
+			attributes["gen_ai.usage.reasoning_tokens"] = strconv.Itoa(u.ReasoningTokens)
+		}
+	}
+	if estimated {
+		attributes["gen_ai.usage.estimated"] = "true"
+	}
+	if cost, ok := c.costTable.cost(opts.Model, inputUsage, outputUsage); ok {
+		attributes["gen_ai.usage.cost_usd"] = strconv.FormatFloat(cost, 'f', -1, 64)
+	}
+
+	if opts.FinishReason != "" {
+		attributes["gen_ai.response.finish_reasons"] = toJSON([]string{opts.FinishReason})
+	}
+
+	// Input messages
+	if len(opts.InputMessages) > 0 {
+		attributes["gen_ai.prompt.messages"] = toJSON(opts.InputMessages)
+	}
+
+	// Output
+	if opts.Output != nil {
+		attributes["gen_ai.completion.message"] = toJSON(opts.Output)
+	}
+
+	// Prompt template, recorded apart from the filled messages so calls can
+	// be grouped by template.
+	if opts.PromptTemplate != "" {
+		sum := sha256.Sum256([]byte(opts.PromptTemplate))
+		attributes["gen_ai.prompt.template"] = opts.PromptTemplate
+		attributes["gen_ai.prompt.template_hash"] = hex.EncodeToString(sum[:])
+	}
+	if opts.PromptVariables != nil {
+		attributes["gen_ai.prompt.variables"] = toJSON(opts.PromptVariables)
+	}
+
+	for k, v := range extra {
+		attributes[k] = v
+	}
+
+	// Additional metadata
+	applyMetadata(attributes, opts.Metadata)
+
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
+	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity)
+
+	var parentSpanID *string
+	if parentID != "" {
+		parentSpanID = &parentID
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = "unknown"
+	}
+
+	span := SpanInput{
+		SpanID:       edgeID,
+		TraceID:      strconv.FormatInt(sessionID, 10),
+		ParentSpanID: parentSpanID,
+		Name:         fmt.Sprintf("%s-%s", operationName, model),
+		StartTime:    startTimeUs,
+		EndTime:      &endTimeUs,
+		Attributes:   attributes,
+	}
+
+	err := c.emitSpan(ctx, tenantID, sessionID, SpanTypeGeneration, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GenAITraceResult{
+		EdgeID:    edgeID,
+		TenantID:  tenantID,
+		AgentID:   agentID,
+		SessionID: sessionID,
+		Model:     opts.Model,
+	}, nil
+}
+
+// CreateToolTrace creates a tool call trace.
+func (c *Client) CreateToolTrace(ctx context.Context, opts CreateToolTraceOptions) (*ToolTraceResult, error) {
+	if opts.Error != nil && opts.ToolOutput != nil {
+		err := &ValidationError{Field: "Error", Reason: "cannot be set together with ToolOutput"}
+		if !c.fireAndForget {
+			return nil, err
+		}
+		c.logger.WarnContext(ctx, "ignoring tool output of failed tool call", "tool", opts.ToolName, errorAttr(err))
+		opts.ToolOutput = nil
+	}
+	if !opts.ToolStartTime.IsZero() && !opts.ToolEndTime.IsZero() && opts.ToolEndTime.Before(opts.ToolStartTime) {
+		err := &ValidationError{Field: "ToolEndTime", Reason: "must not be before ToolStartTime"}
+		if !c.fireAndForget {
+			return nil, err
+		}
+		c.logger.WarnContext(ctx, "ignoring tool execution window", "tool", opts.ToolName, errorAttr(err))
+		opts.ToolStartTime, opts.ToolEndTime = time.Time{}, time.Time{}
+	}
+
+	edgeID := generateEdgeID()
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	start, end := opts.StartTime, opts.EndTime
+	if start.IsZero() {
+		start = opts.ToolStartTime
+	}
+	if end.IsZero() {
+		end = opts.ToolEndTime
+	}
+	startTimeUs, endTimeUs := spanTimes(start, end)
+
+	attributes := map[string]string{
+		"tenant_id":        strconv.FormatInt(tenantID, 10),
+		"project_id":       strconv.FormatInt(projectID, 10),
+		"agent_id":         strconv.FormatInt(agentID, 10),
+		"session_id":       strconv.FormatInt(sessionID, 10),
+		"span_type":        "3", // TOOL_CALL
+		"gen_ai.tool.name": opts.ToolName,
+	}
+
+	if opts.ToolDescription != "" {
+		attributes["gen_ai.tool.description"] = opts.ToolDescription
+	}
+	if opts.ToolInput != nil {
+		attributes["gen_ai.tool.call.input"] = toJSON(opts.ToolInput)
+	}
+	if opts.ToolOutput != nil {
+		attributes["gen_ai.tool.call.output"] = toJSON(opts.ToolOutput)
+	}
+	if !opts.ToolStartTime.IsZero() && !opts.ToolEndTime.IsZero() {
+		attributes["gen_ai.tool.execution_us"] = strconv.FormatInt(opts.ToolEndTime.Sub(opts.ToolStartTime).Microseconds(), 10)
+	}
+	if !opts.StartTime.IsZero() && opts.ToolStartTime.After(opts.StartTime) {
+		attributes["gen_ai.tool.queue_us"] = strconv.FormatInt(opts.ToolStartTime.Sub(opts.StartTime).Microseconds(), 10)
+	}
+	spanType := SpanTypeToolCall
+	if opts.Error != nil {
+		spanType = SpanTypeError
+		attributes["span_type"] = strconv.Itoa(int(SpanTypeError))
+		attributes["gen_ai.tool.call.error"] = opts.Error.Error()
+	}
+
+	// Additional metadata
+	applyMetadata(attributes, opts.Metadata)
+
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
+	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity)
+
+	var parentSpanID *string
+	if parentID != "" {
+		parentSpanID = &parentID
+	}
+
+	span := SpanInput{
+		SpanID:       edgeID,
+		TraceID:      strconv.FormatInt(sessionID, 10),
+		ParentSpanID: parentSpanID,
+		Name:         "tool-" + opts.ToolName,
+		StartTime:    startTimeUs,
+		EndTime:      &endTimeUs,
+		Attributes:   attributes,
+		Events:       progressEvents(opts.Progress),
+	}
+
+	err := c.emitSpan(ctx, tenantID, sessionID, spanType, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolTraceResult{
+		EdgeID:    edgeID,
+		TenantID:  tenantID,
+		AgentID:   agentID,
+		SessionID: sessionID,
+		ToolName:  opts.ToolName,
+	}, nil
+}
+
+// UpdateToolProgress appends a progress event to an open tool span, so the
+// trace shows how a long-running tool call evolved over time.
+func (c *Client) UpdateToolProgress(ctx context.Context, edgeID string, progress ToolProgress) error {
+	payload := map[string]interface{}{"events": []SpanEvent{progress.event()}}
+	_, err := c.request(ctx, "POST", "/api/v1/traces/"+edgeID+"/events", payload, nil)
+	return err
+}
+
+// progressEvents converts tool progress reports into span events.
+func progressEvents(progress []ToolProgress) []SpanEvent {
+	if len(progress) == 0 {
+		return nil
+	}
+	events := make([]SpanEvent, len(progress))
+	for i, p := range progress {
+		events[i] = p.event()
+	}
+	return events
+}
+
+// CreateDecisionTrace records a rule-based or heuristic decision that did not
+// involve an LLM call, so control-flow choices show up in the trace tree.
+func (c *Client) CreateDecisionTrace(ctx context.Context, opts CreateDecisionTraceOptions) (*DecisionTraceResult, error) {
+	edgeID := generateEdgeID()
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	startTimeUs, endTimeUs := spanTimes(opts.StartTime, opts.EndTime)
+
+	attributes := map[string]string{
+		"tenant_id":     strconv.FormatInt(tenantID, 10),
+		"project_id":    strconv.FormatInt(projectID, 10),
+		"agent_id":      strconv.FormatInt(agentID, 10),
+		"session_id":    strconv.FormatInt(sessionID, 10),
+		"span_type":     "12", // FUNCTION
+		"decision.name": opts.DecisionName,
+	}
+
+	if opts.ChosenBranch != "" {
+		attributes["decision.chosen_branch"] = opts.ChosenBranch
+	}
+	if len(opts.Candidates) > 0 {
+		attributes["decision.candidates"] = toJSON(opts.Candidates)
+	}
+	if opts.Reason != "" {
+		attributes["decision.reason"] = opts.Reason
+	}
+
+	// Additional metadata
+	applyMetadata(attributes, opts.Metadata)
+
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
+	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity)
+
+	var parentSpanID *string
+	if parentID != "" {
+		parentSpanID = &parentID
+	}
+
+	span := SpanInput{
+		SpanID:       edgeID,
+		TraceID:      strconv.FormatInt(sessionID, 10),
+		ParentSpanID: parentSpanID,
+		Name:         "decision-" + opts.DecisionName,
+		StartTime:    startTimeUs,
+		EndTime:      &endTimeUs,
+		Attributes:   attributes,
+	}
+
+	err := c.emitSpan(ctx, tenantID, sessionID, SpanTypeFunction, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecisionTraceResult{
+		EdgeID:       edgeID,
+		TenantID:     tenantID,
+		AgentID:      agentID,
+		SessionID:    sessionID,
+		DecisionName: opts.DecisionName,
+	}, nil
+}
+
+// CreateReasoningTrace records a reasoning (chain-of-thought) step. The text
+// is stored under reasoning.content and the span is always flagged
+// SensitivityNoEmbed, so the server keeps it out of the vector index.
+func (c *Client) CreateReasoningTrace(ctx context.Context, opts CreateReasoningTraceOptions) (*ReasoningTraceResult, error) {
+	edgeID := generateEdgeID()
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	startTimeUs, endTimeUs := spanTimes(opts.StartTime, opts.EndTime)
+
+	attributes := map[string]string{
+		"tenant_id":         strconv.FormatInt(tenantID, 10),
+		"project_id":        strconv.FormatInt(projectID, 10),
+		"agent_id":          strconv.FormatInt(agentID, 10),
+		"session_id":        strconv.FormatInt(sessionID, 10),
+		"span_type":         "2", // REASONING
+		"reasoning.content": opts.Content,
+	}
+
+	name := "reasoning"
+	if opts.StepNumber > 0 {
+		attributes["reasoning.step"] = strconv.Itoa(opts.StepNumber)
+		name = "reasoning-step-" + strconv.Itoa(opts.StepNumber)
+	}
+
+	// Additional metadata
+	applyMetadata(attributes, opts.Metadata)
+
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
+	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity|SensitivityNoEmbed)
+
+	var parentSpanID *string
+	if parentID != "" {
+		parentSpanID = &parentID
+	}
+
+	span := SpanInput{
+		SpanID:       edgeID,
+		TraceID:      strconv.FormatInt(sessionID, 10),
+		ParentSpanID: parentSpanID,
+		Name:         name,
+		StartTime:    startTimeUs,
+		EndTime:      &endTimeUs,
+		Attributes:   attributes,
+	}
+
+	err := c.emitSpan(ctx, tenantID, sessionID, SpanTypeReasoning, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReasoningTraceResult{
+		EdgeID:     edgeID,
+		TenantID:   tenantID,
+		AgentID:    agentID,
+		SessionID:  sessionID,
+		StepNumber: opts.StepNumber,
+	}, nil
+}
+
+// CreateGuardrailTrace records the outcome of an input or output guardrail,
+// such as a moderation check. Blocked results are recorded as error spans.
+func (c *Client) CreateGuardrailTrace(ctx context.Context, opts CreateGuardrailTraceOptions) (*GuardrailTraceResult, error) {
+	edgeID := generateEdgeID()
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	startTimeUs, endTimeUs := spanTimes(opts.StartTime, opts.EndTime)
+
+	spanType := SpanTypeFunction
+	if opts.Action == GuardrailActionBlock {
+		spanType = SpanTypeError
+	}
+
+	attributes := map[string]string{
+		"tenant_id":        strconv.FormatInt(tenantID, 10),
+		"project_id":       strconv.FormatInt(projectID, 10),
+		"agent_id":         strconv.FormatInt(agentID, 10),
+		"session_id":       strconv.FormatInt(sessionID, 10),
+		"span_type":        strconv.Itoa(int(spanType)),
+		"guardrail.name":   opts.GuardrailName,
+		"guardrail.passed": strconv.FormatBool(opts.Passed),
+	}
+
+	if opts.Action != "" {
+		attributes["guardrail.action"] = string(opts.Action)
+	}
+	for category, score := range opts.Categories {
+		attributes["guardrail.category."+category] = strconv.FormatFloat(score, 'f', -1, 64)
+	}
+
+	// Additional metadata
+	applyMetadata(attributes, opts.Metadata)
+
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
+	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity)
+
+	var parentSpanID *string
+	if parentID != "" {
+		parentSpanID = &parentID
+	}
+
+	span := SpanInput{
+		SpanID:       edgeID,
+		TraceID:      strconv.FormatInt(sessionID, 10),
+		ParentSpanID: parentSpanID,
+		Name:         "guardrail-" + opts.GuardrailName,
+		StartTime:    startTimeUs,
+		EndTime:      &endTimeUs,
+		Attributes:   attributes,
+	}
+
+	err := c.emitSpan(ctx, tenantID, sessionID, spanType, span)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GuardrailTraceResult{
+		EdgeID:        edgeID,
+		TenantID:      tenantID,
+		AgentID:       agentID,
+		SessionID:     sessionID,
+		GuardrailName: opts.GuardrailName,
+		Passed:        opts.Passed,
+	}, nil
+}
+
+// CreateEmbeddingTrace records a call to an embedding model. The system is
+// detected from the model name as in CreateGenAITrace, and the call is
+// priced with the cost table when the model has an entry.
+func (c *Client) CreateEmbeddingTrace(ctx context.Context, opts CreateEmbeddingTraceOptions) (*EmbeddingTraceResult, error) {
+	edgeID := generateEdgeID()
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	startTimeUs, endTimeUs := spanTimes(opts.StartTime, opts.EndTime)
+
+	attributes := map[string]string{
+		"tenant_id":             strconv.FormatInt(tenantID, 10),
+		"project_id":            strconv.FormatInt(projectID, 10),
+		"agent_id":              strconv.FormatInt(agentID, 10),
+		"session_id":            strconv.FormatInt(sessionID, 10),
+		"span_type":             "9", // EMBEDDING
+		"gen_ai.operation.name": "embeddings",
+	}
+
+	system := opts.System
+	if system == "" && opts.Model != "" {
+		system = detectSystem(opts.Model)
 	}
-
 	if system != "" {
 		attributes["gen_ai.system"] = system
 	}
-
 	if opts.Model != "" {
 		attributes["gen_ai.request.model"] = opts.Model
-		attributes["gen_ai.response.model"] = opts.Model
 	}
-
-	// Model parameters
-	if opts.ModelParameters != nil {
-		for k, v := range opts.ModelParameters {
-			attributes["gen_ai.request."+k] = fmt.Sprintf("%v", v)
-		}
+	if opts.InputCount > 0 {
+		attributes["embedding.input_count"] = strconv.Itoa(opts.InputCount)
+	}
+	if opts.Dimensions > 0 {
+		attributes["embedding.dimensions"] = strconv.Itoa(opts.Dimensions)
 	}
-
-	// Token usage
 	if opts.InputUsage != nil {
-		attributes["gen_ai.usage.prompt_tokens"] = strconv.Itoa(*opts.InputUsage)
 		attributes["gen_ai.usage.input_tokens"] = strconv.Itoa(*opts.InputUsage)
+		attributes["token_count"] = strconv.Itoa(*opts.InputUsage)
 	}
-	if opts.OutputUsage != nil {
-		attributes["gen_ai.usage.completion_tokens"] = strconv.Itoa(*opts.OutputUsage)
-		attributes["gen_ai.usage.output_tokens"] = strconv.Itoa(*opts.OutputUsage)
-	}
-	if opts.TotalUsage != nil {
-		attributes["gen_ai.usage.total_tokens"] = strconv.Itoa(*opts.TotalUsage)
-		attributes["token_count"] = strconv.Itoa(*opts.TotalUsage)
-	}
-
-	if opts.FinishReason != "" {
-		attributes["gen_ai.response.finish_reasons"] = toJSON([]string{opts.FinishReason})
-	}
-
-	// Input messages
-	if len(opts.InputMessages) > 0 {
-		attributes["gen_ai.prompt.messages"] = toJSON(opts.InputMessages)
-	}
-
-	// Output
-	if opts.Output != nil {
-		attributes["gen_ai.completion.message"] = toJSON(opts.Output)
+	if cost, ok := c.costTable.cost(opts.Model, opts.InputUsage, nil); ok {
+		attributes["gen_ai.usage.cost_usd"] = strconv.FormatFloat(cost, 'f', -1, 64)
 	}
 
 	// Additional metadata
-	if opts.Metadata != nil {
-		for k, v := range opts.Metadata {
-			if _, exists := attributes[k]; !exists {
-				switch val := v.(type) {
-				case string:
-					attributes["metadata."+k] = val
-				default:
-					attributes["metadata."+k] = toJSON(v)
-				}
-			}
-		}
+	applyMetadata(attributes, opts.Metadata)
+
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
 	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity)
 
 	var parentSpanID *string
-	if opts.ParentID != "" {
-		parentSpanID = &opts.ParentID
+	if parentID != "" {
+		parentSpanID = &parentID
 	}
 
 	model := opts.Model
@@ -348,94 +1383,103 @@ func (c *Client) CreateGenAITrace(ctx context.Context, opts CreateGenAITraceOpti
 		SpanID:       edgeID,
 		TraceID:      strconv.FormatInt(sessionID, 10),
 		ParentSpanID: parentSpanID,
-		Name:         fmt.Sprintf("%s-%s", operationName, model),
+		Name:         "embeddings-" + model,
 		StartTime:    startTimeUs,
-		EndTime:      &startTimeUs,
+		EndTime:      &endTimeUs,
 		Attributes:   attributes,
 	}
 
-	_, err := c.request(ctx, "POST", "/api/v1/traces", map[string]interface{}{"spans": []SpanInput{span}}, nil)
+	err := c.emitSpan(ctx, tenantID, sessionID, SpanTypeEmbedding, span)
 	if err != nil {
 		return nil, err
 	}
 
-	return &GenAITraceResult{
+	return &EmbeddingTraceResult{
 		EdgeID:    edgeID,
-		TenantID:  c.tenantID,
-		AgentID:   opts.AgentID,
+		TenantID:  tenantID,
+		AgentID:   agentID,
 		SessionID: sessionID,
 		Model:     opts.Model,
 	}, nil
 }
 
-// CreateToolTrace creates a tool call trace.
-func (c *Client) CreateToolTrace(ctx context.Context, opts CreateToolTraceOptions) (*ToolTraceResult, error) {
+// CreateRetrievalTrace records a retrieval step, such as a vector store
+// lookup in a RAG pipeline, with the query and the documents it returned.
+func (c *Client) CreateRetrievalTrace(ctx context.Context, opts CreateRetrievalTraceOptions) (*RetrievalTraceResult, error) {
 	edgeID := generateEdgeID()
-	sessionID := opts.SessionID
-	if sessionID == 0 {
-		sessionID = c.nextSessionID()
-	}
-	startTimeUs := nowMicroseconds()
+	sessionID, parentID := c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	tenantID, projectID, agentID := c.scopeIDs(opts.TenantID, opts.ProjectID, opts.AgentID)
+	startTimeUs, endTimeUs := spanTimes(opts.StartTime, opts.EndTime)
 
-	attributes := map[string]string{
-		"tenant_id":        strconv.FormatInt(c.tenantID, 10),
-		"project_id":       strconv.FormatInt(c.projectID, 10),
-		"agent_id":         strconv.FormatInt(opts.AgentID, 10),
-		"session_id":       strconv.FormatInt(sessionID, 10),
-		"span_type":        "3", // TOOL_CALL
-		"gen_ai.tool.name": opts.ToolName,
+	topK := opts.TopK
+	if topK == 0 {
+		topK = len(opts.Documents)
 	}
 
-	if opts.ToolDescription != "" {
-		attributes["gen_ai.tool.description"] = opts.ToolDescription
-	}
-	if opts.ToolInput != nil {
-		attributes["gen_ai.tool.call.input"] = toJSON(opts.ToolInput)
-	}
-	if opts.ToolOutput != nil {
-		attributes["gen_ai.tool.call.output"] = toJSON(opts.ToolOutput)
+	attributes := map[string]string{
+		"tenant_id":                strconv.FormatInt(tenantID, 10),
+		"project_id":               strconv.FormatInt(projectID, 10),
+		"agent_id":                 strconv.FormatInt(agentID, 10),
+		"session_id":               strconv.FormatInt(sessionID, 10),
+		"span_type":                "8", // RETRIEVAL
+		"retrieval.query":          opts.Query,
+		"retrieval.top_k":          strconv.Itoa(topK),
+		"retrieval.document_count": strconv.Itoa(len(opts.Documents)),
+	}
+
+	if opts.VectorStore != "" {
+		attributes["retrieval.vector_store"] = opts.VectorStore
+	}
+	if len(opts.Documents) > 0 {
+		scores := make([]float64, len(opts.Documents))
+		for i, doc := range opts.Documents {
+			scores[i] = doc.Score
+		}
+		attributes["retrieval.documents"] = toJSON(opts.Documents)
+		attributes["retrieval.scores"] = toJSON(scores)
 	}
 
 	// Additional metadata
-	if opts.Metadata != nil {
-		for k, v := range opts.Metadata {
-			if _, exists := attributes[k]; !exists {
-				switch val := v.(type) {
-				case string:
-					attributes["metadata."+k] = val
-				default:
-					attributes["metadata."+k] = toJSON(v)
-				}
-			}
-		}
+	applyMetadata(attributes, opts.Metadata)
+
+	if endTimeUs > startTimeUs {
+		attributes["duration_us"] = strconv.FormatInt(endTimeUs-startTimeUs, 10)
 	}
+	applyTags(attributes, opts.Tags)
+	applyMetrics(attributes, opts.Metrics)
+	applySensitivity(attributes, opts.Sensitivity)
 
 	var parentSpanID *string
-	if opts.ParentID != "" {
-		parentSpanID = &opts.ParentID
+	if parentID != "" {
+		parentSpanID = &parentID
+	}
+
+	name := "retrieval"
+	if opts.VectorStore != "" {
+		name += "-" + opts.VectorStore
 	}
 
 	span := SpanInput{
 		SpanID:       edgeID,
 		TraceID:      strconv.FormatInt(sessionID, 10),
 		ParentSpanID: parentSpanID,
-		Name:         "tool-" + opts.ToolName,
+		Name:         name,
 		StartTime:    startTimeUs,
-		EndTime:      &startTimeUs,
+		EndTime:      &endTimeUs,
 		Attributes:   attributes,
 	}
 
-	_, err := c.request(ctx, "POST", "/api/v1/traces", map[string]interface{}{"spans": []SpanInput{span}}, nil)
+	err := c.emitSpan(ctx, tenantID, sessionID, SpanTypeRetrieval, span)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ToolTraceResult{
-		EdgeID:    edgeID,
-		TenantID:  c.tenantID,
-		AgentID:   opts.AgentID,
-		SessionID: sessionID,
-		ToolName:  opts.ToolName,
+	return &RetrievalTraceResult{
+		EdgeID:        edgeID,
+		TenantID:      tenantID,
+		AgentID:       agentID,
+		SessionID:     sessionID,
+		DocumentCount: len(opts.Documents),
 	}, nil
 }
 
@@ -488,13 +1532,186 @@ func (c *Client) UpdateTrace(ctx context.Context, opts UpdateTraceOptions) error
 		Attributes:   attributes,
 	}
 
-	_, err := c.request(ctx, "POST", "/api/v1/traces", map[string]interface{}{"spans": []SpanInput{span}}, nil)
+	_, err := c.sendSpans(ctx, c.tenantID, []SpanInput{span})
+	return err
+}
+
+// ReparentSpan moves an existing span under a new parent without
+// re-ingesting it. The new parent must belong to sessionID, and must not be
+// the span itself or one of its descendants.
+func (c *Client) ReparentSpan(ctx context.Context, edgeID, newParentID string, sessionID int64) error {
+	if edgeID == newParentID {
+		return fmt.Errorf("cannot reparent span %s under itself", edgeID)
+	}
+
+	parent, err := c.GetTrace(ctx, newParentID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch new parent: %w", err)
+	}
+	if parent.SessionID != sessionID {
+		return fmt.Errorf("new parent %s belongs to session %d, not %d", newParentID, parent.SessionID, sessionID)
+	}
+
+	tree, err := c.GetTraceTree(ctx, edgeID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch span tree: %w", err)
+	}
+	cycle := false
+	walkTree(tree.Root, func(node TraceTreeNode) {
+		if node.EdgeID == newParentID {
+			cycle = true
+		}
+	})
+	if cycle {
+		return fmt.Errorf("cannot reparent span %s under its descendant %s", edgeID, newParentID)
+	}
+
+	payload := map[string]interface{}{
+		"parent_span_id": newParentID,
+		"session_id":     sessionID,
+	}
+	_, err = c.request(ctx, "PATCH", "/api/v1/traces/"+edgeID, payload, nil)
+	return err
+}
+
+// UpdateSpan amends an existing span in place, for outputs that arrive after
+// the span was created. The server merges attrs into the span's attributes:
+// keys in attrs replace existing values, and all other attributes, along
+// with the span's type, timing and parent, are left as they were. The SDK's
+// identity attributes (tenant_id, project_id, agent_id, session_id and
+// span_type) cannot be changed this way. The redactor, if any, is applied.
+func (c *Client) UpdateSpan(ctx context.Context, edgeID string, attrs map[string]string) error {
+	if err := checkReservedAttributes(attrs); err != nil {
+		return err
+	}
+	return c.patchAttributes(ctx, edgeID, attrs)
+}
+
+// checkReservedAttributes rejects updates to the SDK's identity attributes.
+func checkReservedAttributes(attrs map[string]string) error {
+	for _, key := range []string{"tenant_id", "project_id", "agent_id", "session_id", "span_type"} {
+		if _, ok := attrs[key]; ok {
+			return fmt.Errorf("cannot update reserved attribute %q", key)
+		}
+	}
+	return nil
+}
+
+// patchAttributes merges attrs into the attributes of an existing span.
+func (c *Client) patchAttributes(ctx context.Context, edgeID string, attrs map[string]string) error {
+	if c.redactor != nil {
+		attrs = c.redactAttributes(attrs)
+	}
+	payload := map[string]interface{}{
+		"attributes": attrs,
+	}
+	_, err := c.request(ctx, "PATCH", "/api/v1/traces/"+edgeID, payload, nil)
 	return err
 }
 
-// IngestBatch ingests multiple spans in a batch.
+// IngestBatch ingests multiple spans in a batch. Spans are reordered so that
+// any parent in the batch precedes its children; the server would otherwise
+// briefly see the children as orphans.
+//
+// Every span is validated first, and nothing is sent if any is invalid; see
+// SpanInput.Validate and WithSkipValidation.
+//
+// With WithMaxBatchSize, larger batches are split into several requests and
+// the returned counts cover all of them. A failed chunk does not stop the
+// rest: IngestBatch returns the combined response for the chunks that
+// succeeded together with an error naming each failed span range.
 func (c *Client) IngestBatch(ctx context.Context, spans []SpanInput) (*IngestResponse, error) {
-	respBody, err := c.request(ctx, "POST", "/api/v1/traces", map[string]interface{}{"spans": spans}, nil)
+	if !c.skipValidation {
+		for i := range spans {
+			if err := spans[i].Validate(); err != nil {
+				return nil, fmt.Errorf("span %d: %w", i, err)
+			}
+		}
+	}
+
+	ordered := orderParentsFirst(spans)
+	size := c.maxBatchSize
+	if size <= 0 || size >= len(ordered) {
+		return c.ingestChunk(ctx, ordered)
+	}
+
+	total := &IngestResponse{}
+	var errs []error
+	for start := 0; start < len(ordered); start += size {
+		end := start + size
+		if end > len(ordered) {
+			end = len(ordered)
+		}
+		resp, err := c.ingestChunk(ctx, ordered[start:end])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("spans %d-%d: %w", start, end-1, err))
+			continue
+		}
+		total.Accepted += resp.Accepted
+		total.Rejected += resp.Rejected
+		total.Errors = append(total.Errors, resp.Errors...)
+	}
+	if len(errs) > 0 {
+		return total, fmt.Errorf("failed to ingest %d of %d chunks: %w", len(errs), (len(ordered)+size-1)/size, errors.Join(errs...))
+	}
+	return total, nil
+}
+
+// ingestChunk sends one ingestion request and decodes its response.
+func (c *Client) ingestChunk(ctx context.Context, spans []SpanInput) (*IngestResponse, error) {
+	respBody, err := c.sendSpans(ctx, c.tenantID, spans)
+	if err != nil {
+		return nil, err
+	}
+	if respBody == nil {
+		return &IngestResponse{}, nil
+	}
+
+	var resp IngestResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// orderParentsFirst returns spans reordered so every span whose parent is in
+// the same batch comes after that parent. The relative order is otherwise
+// preserved, and parent cycles are broken at the first span visited.
+func orderParentsFirst(spans []SpanInput) []SpanInput {
+	index := make(map[string]int, len(spans))
+	for i, span := range spans {
+		index[span.SpanID] = i
+	}
+
+	ordered := make([]SpanInput, 0, len(spans))
+	visited := make([]bool, len(spans))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		if parent := spans[i].ParentSpanID; parent != nil {
+			if p, ok := index[*parent]; ok {
+				visit(p)
+			}
+		}
+		ordered = append(ordered, spans[i])
+	}
+	for i := range spans {
+		visit(i)
+	}
+	return ordered
+}
+
+// ValidateBatchRemote asks the server to validate spans against its own
+// rules (schema, quotas) without persisting anything. The response reports
+// what would have been accepted and rejected. This relies on server support
+// for the validate_only param; a server that ignores it ingests the batch.
+func (c *Client) ValidateBatchRemote(ctx context.Context, spans []SpanInput) (*IngestResponse, error) {
+	params := map[string]string{"validate_only": "true"}
+	respBody, err := c.request(ctx, "POST", "/api/v1/traces", map[string]interface{}{"spans": spans}, params)
 	if err != nil {
 		return nil, err
 	}
@@ -521,9 +1738,21 @@ func (c *Client) QueryTraces(ctx context.Context, filter *QueryFilter) (*QueryRe
 		if filter.SessionID != nil {
 			params["session_id"] = strconv.FormatInt(*filter.SessionID, 10)
 		}
+		if filter.Model != nil {
+			params["model"] = *filter.Model
+		}
 		if filter.Environment != "" {
 			params["environment"] = string(filter.Environment)
 		}
+		applySpanTypeFilter(params, filter)
+		applyTagFilter(params, filter)
+		applyAttributeFilter(params, filter)
+		applyFeedbackFilter(params, filter)
+		applyDurationFilter(params, filter)
+		applyConfidenceFilter(params, filter)
+		if filter.Synthetic != nil {
+			params["synthetic"] = strconv.FormatBool(*filter.Synthetic)
+		}
 		if filter.ExcludePII {
 			params["exclude_pii"] = "true"
 		}
@@ -538,6 +1767,13 @@ func (c *Client) QueryTraces(ctx context.Context, filter *QueryFilter) (*QueryRe
 		}
 	}
 
+	return c.queryTraces(ctx, params)
+}
+
+// queryTraces runs a trace query with the given params. When the server
+// doesn't echo the filters it applied, the params that were sent are
+// reported in AppliedFilters instead.
+func (c *Client) queryTraces(ctx context.Context, params map[string]string) (*QueryResponse, error) {
 	respBody, err := c.request(ctx, "GET", "/api/v1/traces", nil, params)
 	if err != nil {
 		return nil, err
@@ -547,6 +1783,9 @@ func (c *Client) QueryTraces(ctx context.Context, filter *QueryFilter) (*QueryRe
 	if err := json.Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+	if resp.AppliedFilters == nil {
+		resp.AppliedFilters = params
+	}
 
 	return &resp, nil
 }
@@ -565,9 +1804,21 @@ func (c *Client) QueryTemporalRange(ctx context.Context, startUs, endUs int64, f
 		if filter.AgentID != nil {
 			params["agent_id"] = strconv.FormatInt(*filter.AgentID, 10)
 		}
+		if filter.Model != nil {
+			params["model"] = *filter.Model
+		}
 		if filter.Environment != "" {
 			params["environment"] = string(filter.Environment)
 		}
+		applySpanTypeFilter(params, filter)
+		applyTagFilter(params, filter)
+		applyAttributeFilter(params, filter)
+		applyFeedbackFilter(params, filter)
+		applyDurationFilter(params, filter)
+		applyConfidenceFilter(params, filter)
+		if filter.Synthetic != nil {
+			params["synthetic"] = strconv.FormatBool(*filter.Synthetic)
+		}
 		if filter.ExcludePII {
 			params["exclude_pii"] = "true"
 		}
@@ -579,12 +1830,17 @@ func (c *Client) QueryTemporalRange(ctx context.Context, startUs, endUs int64, f
 		}
 	}
 
-	respBody, err := c.request(ctx, "GET", "/api/v1/traces", nil, params)
+	return c.queryTraces(ctx, params)
+}
+
+// GetTrace gets a specific trace by ID.
+func (c *Client) GetTrace(ctx context.Context, traceID string) (*TraceView, error) {
+	respBody, err := c.request(ctx, "GET", "/api/v1/traces/"+traceID, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp QueryResponse
+	var resp TraceView
 	if err := json.Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
@@ -592,14 +1848,24 @@ func (c *Client) QueryTemporalRange(ctx context.Context, startUs, endUs int64, f
 	return &resp, nil
 }
 
-// GetTrace gets a specific trace by ID.
-func (c *Client) GetTrace(ctx context.Context, traceID string) (*TraceView, error) {
+// GetTraceRaw gets a specific trace by ID as the unparsed JSON body, for
+// inspecting server fields that TraceView doesn't model yet.
+func (c *Client) GetTraceRaw(ctx context.Context, traceID string) (json.RawMessage, error) {
 	respBody, err := c.request(ctx, "GET", "/api/v1/traces/"+traceID, nil, nil)
 	if err != nil {
 		return nil, err
 	}
+	return json.RawMessage(respBody), nil
+}
 
-	var resp TraceView
+// GetTraceTree gets the hierarchical trace tree.
+func (c *Client) GetTraceTree(ctx context.Context, traceID string) (*TraceTreeResponse, error) {
+	respBody, err := c.request(ctx, "GET", "/api/v1/traces/"+traceID+"/tree", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TraceTreeResponse
 	if err := json.Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
@@ -607,9 +1873,24 @@ func (c *Client) GetTrace(ctx context.Context, traceID string) (*TraceView, erro
 	return &resp, nil
 }
 
-// GetTraceTree gets the hierarchical trace tree.
-func (c *Client) GetTraceTree(ctx context.Context, traceID string) (*TraceTreeResponse, error) {
-	respBody, err := c.request(ctx, "GET", "/api/v1/traces/"+traceID+"/tree", nil, nil)
+// GetTraceTreeWithOptions gets a trace tree pruned by depth and span type.
+// The options are sent to the server as max_depth and span_types params.
+// Servers that ignore them return the full tree, so the result is pruned
+// again client-side; pruning an already pruned tree changes nothing.
+func (c *Client) GetTraceTreeWithOptions(ctx context.Context, traceID string, opts TreeOptions) (*TraceTreeResponse, error) {
+	params := make(map[string]string)
+	if opts.MaxDepth > 0 {
+		params["max_depth"] = strconv.Itoa(opts.MaxDepth)
+	}
+	if len(opts.IncludeSpanTypes) > 0 {
+		types := make([]string, len(opts.IncludeSpanTypes))
+		for i, t := range opts.IncludeSpanTypes {
+			types[i] = strconv.Itoa(int(t))
+		}
+		params["span_types"] = strings.Join(types, ",")
+	}
+
+	respBody, err := c.request(ctx, "GET", "/api/v1/traces/"+traceID+"/tree", nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -619,9 +1900,36 @@ func (c *Client) GetTraceTree(ctx context.Context, traceID string) (*TraceTreeRe
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	resp.Root.Children = pruneTree(resp.Root.Children, opts, 1)
 	return &resp, nil
 }
 
+// DeleteTrace deletes a single trace. Deleting a trace that does not exist
+// is not an error, so retried deletions are safe; other failures are
+// returned as *APIError. The server deletes synchronously, so the trace is
+// gone from queries once DeleteTrace returns.
+func (c *Client) DeleteTrace(ctx context.Context, traceID string) error {
+	return c.deleteResource(ctx, "/api/v1/traces/"+traceID)
+}
+
+// DeleteSession deletes every trace in a session. As with DeleteTrace, a
+// missing session is not an error. The server removes the session's spans
+// one by one and does not report individual failures, so callers that
+// must be certain (for example for data-deletion requests) should confirm
+// with FilterBySession afterwards.
+func (c *Client) DeleteSession(ctx context.Context, sessionID int64) error {
+	return c.deleteResource(ctx, "/api/v1/sessions/"+strconv.FormatInt(sessionID, 10))
+}
+
+// deleteResource issues a DELETE to path, treating 404 as success.
+func (c *Client) deleteResource(ctx context.Context, path string) error {
+	_, err := c.request(ctx, "DELETE", path, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
 // FilterBySession gets all traces in a session.
 func (c *Client) FilterBySession(ctx context.Context, sessionID int64) ([]TraceView, error) {
 	resp, err := c.QueryTraces(ctx, &QueryFilter{SessionID: &sessionID})
@@ -631,13 +1939,51 @@ func (c *Client) FilterBySession(ctx context.Context, sessionID int64) ([]TraceV
 	return resp.Traces, nil
 }
 
+// QueryByAgent gets up to limit traces recorded by an agent. A limit of zero
+// uses the server default.
+func (c *Client) QueryByAgent(ctx context.Context, agentID int64, limit int) ([]TraceView, error) {
+	resp, err := c.QueryTraces(ctx, &QueryFilter{AgentID: &agentID, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Traces, nil
+}
+
+// QueryByModel gets up to limit traces of calls to a model, matched against
+// the requested model name. A limit of zero uses the server default.
+func (c *Client) QueryByModel(ctx context.Context, model string, limit int) ([]TraceView, error) {
+	resp, err := c.QueryTraces(ctx, &QueryFilter{Model: &model, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Traces, nil
+}
+
+// maxFeedbackCommentLength bounds the size of a feedback comment in bytes.
+const maxFeedbackCommentLength = 4096
+
 // SubmitFeedback submits user feedback for a trace.
 func (c *Client) SubmitFeedback(ctx context.Context, traceID string, feedback int) (*FeedbackResponse, error) {
-	if feedback < -1 || feedback > 1 {
-		return nil, fmt.Errorf("feedback must be -1, 0, or 1")
+	return c.SubmitFeedbackWithComment(ctx, traceID, feedback, "", nil)
+}
+
+// SubmitFeedbackWithComment submits user feedback for a trace together with
+// a free-text comment and category tags. The score must be -1, 0, or 1 and
+// the comment at most 4096 bytes; an empty comment and nil tags are omitted.
+func (c *Client) SubmitFeedbackWithComment(ctx context.Context, traceID string, score int, comment string, tags []string) (*FeedbackResponse, error) {
+	if err := validateFeedback(score, comment); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{"feedback": score}
+	if comment != "" {
+		payload["comment"] = comment
+	}
+	if len(tags) > 0 {
+		payload["tags"] = tags
 	}
 
-	respBody, err := c.request(ctx, "POST", "/api/v1/traces/"+traceID+"/feedback", map[string]int{"feedback": feedback}, nil)
+	respBody, err := c.request(ctx, "POST", "/api/v1/traces/"+traceID+"/feedback", payload, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -650,6 +1996,71 @@ func (c *Client) SubmitFeedback(ctx context.Context, traceID string, feedback in
 	return &resp, nil
 }
 
+// validateFeedback checks a feedback score and comment.
+func validateFeedback(score int, comment string) error {
+	if score < -1 || score > 1 {
+		return &ValidationError{Field: "feedback", Reason: "must be -1, 0, or 1"}
+	}
+	if len(comment) > maxFeedbackCommentLength {
+		return &ValidationError{
+			Field:  "comment",
+			Reason: fmt.Sprintf("length %d exceeds %d bytes", len(comment), maxFeedbackCommentLength),
+		}
+	}
+	return nil
+}
+
+// SubmitFeedbackBatch submits feedback for many traces in one request. Every
+// item is validated as in SubmitFeedbackWithComment first, and nothing is
+// sent if any is invalid. The response has one result per item, so a trace
+// the server rejects does not fail the others.
+//
+// Servers without the batch endpoint answer 404; the items are then
+// submitted one by one, with per-item failures reported in the results.
+func (c *Client) SubmitFeedbackBatch(ctx context.Context, items []FeedbackItem) (*FeedbackBatchResponse, error) {
+	for i, item := range items {
+		if err := validateFeedback(item.Score, item.Comment); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	if len(items) == 0 {
+		return &FeedbackBatchResponse{Results: []FeedbackItemResult{}}, nil
+	}
+
+	payload := map[string]interface{}{"items": items}
+	respBody, err := c.request(ctx, "POST", "/api/v1/feedback/batch", payload, nil)
+	if errors.Is(err, ErrNotFound) {
+		return c.submitFeedbackEach(ctx, items), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp FeedbackBatchResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// submitFeedbackEach is SubmitFeedbackBatch's fallback for servers without
+// the batch endpoint.
+func (c *Client) submitFeedbackEach(ctx context.Context, items []FeedbackItem) *FeedbackBatchResponse {
+	resp := &FeedbackBatchResponse{Results: make([]FeedbackItemResult, len(items))}
+	for i, item := range items {
+		result := FeedbackItemResult{TraceID: item.TraceID}
+		if r, err := c.SubmitFeedbackWithComment(ctx, item.TraceID, item.Score, item.Comment, nil); err != nil {
+			result.Message = err.Error()
+		} else {
+			result.Success = r.Success
+			result.Message = r.Message
+		}
+		resp.Results[i] = result
+	}
+	return resp
+}
+
 // AddToDataset adds a trace to an evaluation dataset.
 func (c *Client) AddToDataset(ctx context.Context, traceID, datasetName string, inputData, outputData map[string]interface{}) (*DatasetResponse, error) {
 	payload := map[string]interface{}{
@@ -675,9 +2086,52 @@ func (c *Client) AddToDataset(ctx context.Context, traceID, datasetName string,
 	return &resp, nil
 }
 
-// Health checks server health.
+// ListDatasets lists the evaluation datasets populated through AddToDataset.
+func (c *Client) ListDatasets(ctx context.Context) ([]DatasetInfo, error) {
+	respBody, err := c.request(ctx, "GET", "/api/v1/datasets", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Datasets []DatasetInfo `json:"datasets"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return resp.Datasets, nil
+}
+
+// GetDataset gets a page of a dataset's entries in the order they were
+// added. A limit of zero uses the server default; page through the rest
+// with offset until it reaches DatasetPage.Total.
+func (c *Client) GetDataset(ctx context.Context, name string, limit, offset int) (*DatasetPage, error) {
+	params := make(map[string]string)
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+	if offset > 0 {
+		params["offset"] = strconv.Itoa(offset)
+	}
+
+	respBody, err := c.request(ctx, "GET", "/api/v1/datasets/"+name, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp DatasetPage
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// Health checks server health. The reported version is remembered for
+// ServerVersion.
 func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
-	respBody, err := c.request(ctx, "GET", "/api/v1/health", nil, nil)
+	respBody, err := c.request(ctx, "GET", healthPath, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -687,10 +2141,47 @@ func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	if resp.Version != "" {
+		c.serverVersion.Store(&resp.Version)
+	}
 	return &resp, nil
 }
 
-// Close closes the client and releases resources.
+// defaultCloseTimeout bounds how long Close waits for pending spans.
+const defaultCloseTimeout = 5 * time.Second
+
+// Close is CloseWithContext with a 5 second timeout. Errors are logged
+// rather than returned.
 func (c *Client) Close() {
-	c.httpClient.CloseIdleConnections()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCloseTimeout)
+	defer cancel()
+	if err := c.CloseWithContext(ctx); err != nil {
+		c.logger.Warn("failed to close client cleanly", errorAttr(err))
+	}
+}
+
+// CloseWithContext closes the client and releases resources. With
+// WithAsyncBuffer it first sends any queued spans, and with
+// WithFireAndForget it waits for in-flight sends, both bounded by ctx so a
+// dead server cannot hang shutdown. Spans still queued when ctx ends are
+// dropped. The exporter is closed too if it has a Close method. It returns
+// the flush, context and exporter errors, joined.
+//
+// Closing a clone leaves the HTTP client and any exporter it shares with
+// its parent open.
+func (c *Client) CloseWithContext(ctx context.Context) error {
+	var errs []error
+	if c.buffer != nil {
+		errs = append(errs, c.buffer.close(ctx))
+	}
+	if ctx.Err() == nil {
+		errs = append(errs, c.waitInflight(ctx))
+	}
+	if closer, ok := c.exporter.(exportCloser); ok && !c.sharedExporter {
+		errs = append(errs, closer.Close())
+	}
+	if !c.sharedHTTPClient {
+		c.httpClient.CloseIdleConnections()
+	}
+	return errors.Join(errs...)
 }
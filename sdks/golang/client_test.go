@@ -0,0 +1,238 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newDelayServer starts a server that answers every request with an empty
+// query response after delay, or gives up once the request is cancelled.
+func newDelayServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			w.Write([]byte(`{"traces":[]}`))
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestContextDeadlineShorterThanTimeout(t *testing.T) {
+	srv := newDelayServer(t, time.Minute)
+	c := NewClient(srv.URL, 1, WithTimeout(10*time.Second))
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.QueryTraces(ctx, nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("QueryTraces error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("QueryTraces returned after %s, want about 50ms", elapsed)
+	}
+}
+
+func TestWithTimeoutZero(t *testing.T) {
+	srv := newDelayServer(t, 200*time.Millisecond)
+
+	t.Run("unbounded without deadline", func(t *testing.T) {
+		c := NewClient(srv.URL, 1, WithTimeout(0))
+		defer c.Close()
+		if _, err := c.QueryTraces(context.Background(), nil); err != nil {
+			t.Fatalf("QueryTraces: %v", err)
+		}
+	})
+
+	t.Run("bounded by context", func(t *testing.T) {
+		c := NewClient(srv.URL, 1, WithTimeout(0))
+		defer c.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := c.QueryTraces(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("QueryTraces error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("nonzero timeout applies", func(t *testing.T) {
+		c := NewClient(srv.URL, 1, WithTimeout(50*time.Millisecond))
+		defer c.Close()
+		if _, err := c.QueryTraces(context.Background(), nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("QueryTraces error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+}
+
+// newQueryServer starts a server that answers trace queries with no traces
+// and sends each request's query params on the returned channel.
+func newQueryServer(t *testing.T) (*httptest.Server, <-chan url.Values) {
+	t.Helper()
+	queries := make(chan url.Values, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query()
+		w.Write([]byte(`{"traces":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, queries
+}
+
+// checkParam reports an error unless query has key set to want, or lacks
+// key entirely when want is nil.
+func checkParam(t *testing.T, query url.Values, key string, want *string) {
+	t.Helper()
+	got, ok := query[key]
+	switch {
+	case want == nil && ok:
+		t.Errorf("%s = %q, want it absent", key, got)
+	case want != nil && !ok:
+		t.Errorf("%s is absent, want %q", key, *want)
+	case want != nil && (len(got) != 1 || got[0] != *want):
+		t.Errorf("%s = %q, want %q", key, got, *want)
+	}
+}
+
+func TestQueryTracesFeedbackFilter(t *testing.T) {
+	yes, no := true, false
+	minFeedback, maxFeedback := -1, 1
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   map[string]*string
+	}{
+		{
+			name:   "nil HasFeedback",
+			filter: QueryFilter{},
+			want:   map[string]*string{"has_feedback": nil, "min_feedback": nil, "max_feedback": nil},
+		},
+		{
+			name:   "HasFeedback true",
+			filter: QueryFilter{HasFeedback: &yes},
+			want:   map[string]*string{"has_feedback": strPtr("true")},
+		},
+		{
+			name:   "HasFeedback false",
+			filter: QueryFilter{HasFeedback: &no},
+			want:   map[string]*string{"has_feedback": strPtr("false")},
+		},
+		{
+			name:   "feedback bounds",
+			filter: QueryFilter{MinFeedback: &minFeedback, MaxFeedback: &maxFeedback},
+			want:   map[string]*string{"has_feedback": nil, "min_feedback": strPtr("-1"), "max_feedback": strPtr("1")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, queries := newQueryServer(t)
+			c := NewClient(srv.URL, 1)
+			defer c.Close()
+
+			if _, err := c.QueryTraces(context.Background(), &tt.filter); err != nil {
+				t.Fatalf("QueryTraces: %v", err)
+			}
+			query := <-queries
+			for key, want := range tt.want {
+				checkParam(t, query, key, want)
+			}
+		})
+	}
+}
+
+// queryFuncs are the query methods that forward QueryFilter bounds.
+var queryFuncs = []struct {
+	name  string
+	query func(*Client, *QueryFilter) (*QueryResponse, error)
+}{
+	{"QueryTraces", func(c *Client, f *QueryFilter) (*QueryResponse, error) {
+		return c.QueryTraces(context.Background(), f)
+	}},
+	{"QueryTemporalRange", func(c *Client, f *QueryFilter) (*QueryResponse, error) {
+		return c.QueryTemporalRange(context.Background(), 1000, 2000, f)
+	}},
+}
+
+func TestQueryConfidenceAndDurationFilter(t *testing.T) {
+	minConfidence, maxConfidence := 0.25, 0.9
+	minDuration, maxDuration := int64(500000), int64(2000000)
+	unset := map[string]*string{
+		"min_confidence": nil, "max_confidence": nil,
+		"min_duration_us": nil, "max_duration_us": nil,
+	}
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   map[string]*string
+	}{
+		{name: "unset", filter: QueryFilter{}, want: unset},
+		{
+			name: "all set",
+			filter: QueryFilter{
+				MinConfidence: &minConfidence, MaxConfidence: &maxConfidence,
+				MinDurationUs: &minDuration, MaxDurationUs: &maxDuration,
+			},
+			want: map[string]*string{
+				"min_confidence": strPtr("0.25"), "max_confidence": strPtr("0.9"),
+				"min_duration_us": strPtr("500000"), "max_duration_us": strPtr("2000000"),
+			},
+		},
+		{
+			name:   "min duration only",
+			filter: QueryFilter{MinDurationUs: &minDuration},
+			want:   map[string]*string{"min_duration_us": strPtr("500000"), "max_duration_us": nil},
+		},
+		{
+			name:   "max duration only",
+			filter: QueryFilter{MaxDurationUs: &maxDuration},
+			want:   map[string]*string{"min_duration_us": nil, "max_duration_us": strPtr("2000000")},
+		},
+		{
+			name:   "min confidence only",
+			filter: QueryFilter{MinConfidence: &minConfidence},
+			want:   map[string]*string{"min_confidence": strPtr("0.25"), "max_confidence": nil},
+		},
+	}
+	for _, qf := range queryFuncs {
+		for _, tt := range tests {
+			t.Run(qf.name+"/"+tt.name, func(t *testing.T) {
+				srv, queries := newQueryServer(t)
+				c := NewClient(srv.URL, 1)
+				defer c.Close()
+
+				if _, err := qf.query(c, &tt.filter); err != nil {
+					t.Fatalf("%s: %v", qf.name, err)
+				}
+				query := <-queries
+				for key, want := range tt.want {
+					checkParam(t, query, key, want)
+				}
+			})
+		}
+	}
+}
+
+// strPtr returns a pointer to s, for expected param values.
+func strPtr(s string) *string { return &s }
@@ -0,0 +1,98 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "slices"
+
+// Clone returns a new Client with the same configuration as c, with opts
+// applied on top, e.g. to tag spans with a different agent:
+//
+//	planner := client.Clone(agentreplay.WithAgentID(2))
+//
+// The clone shares c's HTTP client, and so its connection pool, unless opts
+// include WithHTTPClient. Because of that, WithTransportConfig and
+// WithMaxRedirects have no effect on a clone. The rate limiter, WithRollup
+// aggregates and a custom exporter are shared as well. The clone gets its
+// own async buffer, sampling cache and in-flight tracking, and must be
+// closed separately; closing it leaves the shared resources open for c.
+//
+// Auto-generated session IDs come from a counter shared by every Client in
+// the process, clones included, so a clone and its parent never hand out
+// the same session ID.
+func (c *Client) Clone(opts ...ClientOption) *Client {
+	clone := &Client{
+		url:                  c.url,
+		tenantID:             c.tenantID,
+		projectID:            c.projectID,
+		agentID:              c.agentID,
+		timeout:              c.timeout,
+		httpClient:           c.httpClient,
+		customHTTPClient:     c.customHTTPClient,
+		transportConfig:      c.transportConfig,
+		maxRedirects:         c.maxRedirects,
+		maxBatchSize:         c.maxBatchSize,
+		skipValidation:       c.skipValidation,
+		minDeadline:          c.minDeadline,
+		retryAttempts:        c.retryAttempts,
+		retryBaseDelay:       c.retryBaseDelay,
+		idempotency:          c.idempotency,
+		limiter:              c.limiter,
+		rollup:               c.rollup,
+		nameSanitizer:        c.nameSanitizer,
+		maxNameLength:        c.maxNameLength,
+		sourceLocation:       c.sourceLocation,
+		autoName:             c.autoName,
+		redactor:             c.redactor,
+		costTable:            c.costTable,
+		tokenizer:            c.tokenizer,
+		headers:              c.headers.Clone(),
+		headerFunc:           c.headerFunc,
+		apiKey:               c.apiKey,
+		basicAuth:            c.basicAuth,
+		basicAuthUser:        c.basicAuthUser,
+		basicAuthPass:        c.basicAuthPass,
+		compression:          c.compression,
+		compressionThreshold: c.compressionThreshold,
+		logger:               c.logger,
+		metrics:              c.metrics,
+		sampler:              c.sampler,
+		sampleRates:          c.sampleRates,
+		defaultSampleRate:    c.defaultSampleRate,
+		synthetic:            c.synthetic,
+		environment:          c.environment,
+		customEnv:            c.customEnv,
+		protobuf:             c.protobuf,
+		minVersion:           c.minVersion,
+		fireAndForget:        c.fireAndForget,
+		highWaterMark:        c.highWaterMark,
+		spanProcessors:       slices.Clip(c.spanProcessors),
+	}
+	if c.buffer != nil {
+		clone.buffer = &asyncBuffer{maxBatch: c.buffer.maxBatch, flushInterval: c.buffer.flushInterval}
+	}
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+
+	clone.sharedHTTPClient = clone.httpClient == c.httpClient
+	if _, ok := c.exporter.(*httpExporter); !ok && clone.exporter == nil {
+		clone.exporter = c.exporter
+		clone.sharedExporter = true
+	}
+
+	clone.setup()
+	return clone
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// Compression is a request body encoding.
+type Compression string
+
+const (
+	// CompressionNone sends request bodies uncompressed
+	CompressionNone Compression = ""
+	// CompressionGzip gzip-encodes request bodies
+	CompressionGzip Compression = "gzip"
+)
+
+// defaultCompressionThreshold is the smallest body, in bytes, compressed
+// when WithCompressionThreshold is not set.
+const defaultCompressionThreshold = 1024
+
+// WithCompression compresses request bodies larger than the compression
+// threshold (1 KiB unless set with WithCompressionThreshold) and marks them
+// with a Content-Encoding header. Small single-span posts are sent as-is.
+func WithCompression(compression Compression) ClientOption {
+	return func(c *Client) {
+		c.compression = compression
+	}
+}
+
+// WithCompressionThreshold sets the body size in bytes above which
+// WithCompression takes effect.
+func WithCompressionThreshold(bytes int) ClientOption {
+	return func(c *Client) {
+		c.compressionThreshold = bytes
+	}
+}
+
+// compressBody encodes body with the configured compression, returning the
+// Content-Encoding to send, or "" when the body is sent uncompressed.
+func (c *Client) compressBody(body []byte) ([]byte, string, error) {
+	if c.compression != CompressionGzip || len(body) <= c.compressionThreshold {
+		return body, "", nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, "", fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), string(CompressionGzip), nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// ingestRequest is what the test server saw of one ingestion request.
+type ingestRequest struct {
+	contentEncoding string
+	spans           []SpanInput
+}
+
+// newIngestServer starts a server that decodes ingestion requests,
+// gunzipping gzip bodies, and sends each one on the returned channel.
+func newIngestServer(t *testing.T) (*httptest.Server, <-chan ingestRequest) {
+	t.Helper()
+	requests := make(chan ingestRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		encoding := r.Header.Get("Content-Encoding")
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("gzip.NewReader: %v", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer zr.Close()
+			body = zr
+		}
+		var payload struct {
+			Spans []SpanInput `json:"spans"`
+		}
+		if err := json.NewDecoder(body).Decode(&payload); err != nil {
+			t.Errorf("decoding request body: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests <- ingestRequest{contentEncoding: encoding, spans: payload.Spans}
+		json.NewEncoder(w).Encode(IngestResponse{Accepted: len(payload.Spans)})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+// testSpans returns n valid root spans.
+func testSpans(n int) []SpanInput {
+	spans := make([]SpanInput, n)
+	for i := range spans {
+		end := int64(2_000_000 + i)
+		spans[i] = SpanInput{
+			SpanID:     fmt.Sprintf("%016x", i+1),
+			TraceID:    "1",
+			Name:       fmt.Sprintf("span-%d", i),
+			StartTime:  1_000_000 + int64(i),
+			EndTime:    &end,
+			Attributes: map[string]string{"tenant_id": "1", "index": fmt.Sprint(i)},
+		}
+	}
+	return spans
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	const threshold = 512
+	tests := []struct {
+		name     string
+		spans    []SpanInput
+		encoding string
+	}{
+		{name: "above threshold", spans: testSpans(20), encoding: "gzip"},
+		{name: "below threshold", spans: testSpans(1), encoding: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]interface{}{"spans": tt.spans})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if above := len(body) > threshold; above != (tt.encoding == "gzip") {
+				t.Fatalf("body is %d bytes, test case expects above threshold = %v", len(body), !above)
+			}
+
+			srv, requests := newIngestServer(t)
+			c := NewClient(srv.URL, 1, WithCompression(CompressionGzip), WithCompressionThreshold(threshold))
+			defer c.Close()
+
+			resp, err := c.IngestBatch(context.Background(), tt.spans)
+			if err != nil {
+				t.Fatalf("IngestBatch: %v", err)
+			}
+			if resp.Accepted != len(tt.spans) {
+				t.Errorf("Accepted = %d, want %d", resp.Accepted, len(tt.spans))
+			}
+
+			got := <-requests
+			if got.contentEncoding != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want %q", got.contentEncoding, tt.encoding)
+			}
+			if !reflect.DeepEqual(got.spans, tt.spans) {
+				t.Errorf("server received spans %+v, want %+v", got.spans, tt.spans)
+			}
+		})
+	}
+}
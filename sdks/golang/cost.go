@@ -0,0 +1,68 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+// ModelCost is the price of a model in US dollars per 1,000 tokens.
+type ModelCost struct {
+	InputPer1K  float64
+	OutputPer1K float64
+}
+
+// CostTable maps model names, as passed in CreateGenAITraceOptions.Model, to
+// their prices.
+type CostTable map[string]ModelCost
+
+// DefaultCostTable returns a new table with list prices for common OpenAI and
+// Anthropic models. It is what clients use unless WithCostTable is set.
+func DefaultCostTable() CostTable {
+	return CostTable{
+		"gpt-4o":            {InputPer1K: 0.0025, OutputPer1K: 0.01},
+		"gpt-4o-mini":       {InputPer1K: 0.00015, OutputPer1K: 0.0006},
+		"gpt-4-turbo":       {InputPer1K: 0.01, OutputPer1K: 0.03},
+		"gpt-4":             {InputPer1K: 0.03, OutputPer1K: 0.06},
+		"gpt-3.5-turbo":     {InputPer1K: 0.0005, OutputPer1K: 0.0015},
+		"claude-3-5-sonnet": {InputPer1K: 0.003, OutputPer1K: 0.015},
+		"claude-3-5-haiku":  {InputPer1K: 0.0008, OutputPer1K: 0.004},
+		"claude-3-opus":     {InputPer1K: 0.015, OutputPer1K: 0.075},
+		"claude-3-haiku":    {InputPer1K: 0.00025, OutputPer1K: 0.00125},
+	}
+}
+
+// WithCostTable sets the prices CreateGenAITrace uses to attach
+// gen_ai.usage.cost_usd. Models are matched by exact name; calls to models
+// missing from the table get no cost attribute. A nil table disables cost
+// computation.
+func WithCostTable(table CostTable) ClientOption {
+	return func(c *Client) {
+		c.costTable = table
+	}
+}
+
+// cost returns the dollar cost of a call to model, and false when the model
+// has no price or no token usage was reported.
+func (t CostTable) cost(model string, inputTokens, outputTokens *int) (float64, bool) {
+	price, ok := t[model]
+	if !ok || (inputTokens == nil && outputTokens == nil) {
+		return 0, false
+	}
+	var cost float64
+	if inputTokens != nil {
+		cost += float64(*inputTokens) / 1000 * price.InputPer1K
+	}
+	if outputTokens != nil {
+		cost += float64(*outputTokens) / 1000 * price.OutputPer1K
+	}
+	return cost, true
+}
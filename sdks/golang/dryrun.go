@@ -0,0 +1,67 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// WithDryRun builds spans as usual but sends nothing: the Create* methods
+// and IngestBatch run sampling, span processors and redaction, then hand the
+// result to an in-memory exporter instead of the server. Call LastSpans to
+// inspect what would have been sent. Results, including generated EdgeIDs,
+// are returned as normal, and IngestBatch reports every span as accepted.
+//
+// Reads such as QueryTraces still go to the server. WithDryRun replaces any
+// exporter set with WithExporter, and vice versa, depending on order.
+func WithDryRun() ClientOption {
+	return func(c *Client) {
+		c.exporter = &dryRunExporter{}
+	}
+}
+
+// LastSpans returns the spans of the most recent send in dry-run mode,
+// after all processing. A single Create* call yields one span; an
+// IngestBatch split by WithMaxBatchSize yields its last chunk. It returns
+// nil when the client is not in dry-run mode or nothing was sent yet.
+func (c *Client) LastSpans() []SpanInput {
+	if e, ok := c.exporter.(*dryRunExporter); ok {
+		return e.lastSpans()
+	}
+	return nil
+}
+
+// dryRunExporter keeps the most recently exported spans instead of sending
+// them.
+type dryRunExporter struct {
+	mu   sync.Mutex
+	last []SpanInput
+}
+
+// Export implements Exporter.
+func (e *dryRunExporter) Export(ctx context.Context, spans []SpanInput) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.last = slices.Clone(spans)
+	return nil
+}
+
+func (e *dryRunExporter) lastSpans() []SpanInput {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.last)
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"sort"
+)
+
+// DurationStats summarizes span durations, in microseconds.
+type DurationStats struct {
+	Count int     `json:"count"`
+	Min   int64   `json:"min_us"`
+	Max   int64   `json:"max_us"`
+	Mean  float64 `json:"mean_us"`
+	P50   int64   `json:"p50_us"`
+	P95   int64   `json:"p95_us"`
+	P99   int64   `json:"p99_us"`
+}
+
+// AggregateDurations pages through every trace matching filter and returns
+// duration statistics grouped by span type name (e.g. "ToolCall"), as
+// returned by SpanType.String. Unrecognized span types are grouped under
+// their raw value. Percentiles use the nearest-rank method.
+func (c *Client) AggregateDurations(ctx context.Context, filter *QueryFilter) (map[string]DurationStats, error) {
+	durations := make(map[string][]int64)
+	for trace, err := range c.IterateTraces(ctx, filter) {
+		if err != nil {
+			return nil, err
+		}
+		key := trace.SpanType
+		if spanType, ok := parseSpanType(trace.SpanType); ok {
+			key = spanType.String()
+		}
+		durations[key] = append(durations[key], trace.DurationUs)
+	}
+
+	stats := make(map[string]DurationStats, len(durations))
+	for key, values := range durations {
+		stats[key] = durationStats(values)
+	}
+	return stats, nil
+}
+
+// durationStats computes statistics over a non-empty set of durations,
+// sorting values in place.
+func durationStats(values []int64) DurationStats {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	var sum float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	return DurationStats{
+		Count: len(values),
+		Min:   values[0],
+		Max:   values[len(values)-1],
+		Mean:  sum / float64(len(values)),
+		P50:   percentile(values, 50),
+		P95:   percentile(values, 95),
+		P99:   percentile(values, 99),
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted values.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrDeadlineTooShort is returned instead of issuing a request whose context
+// deadline is closer than the minimum set with WithMinDeadline.
+var ErrDeadlineTooShort = errors.New("context deadline too short for request")
+
+// ErrServerTooOld is returned for every request once WithVersionCheck finds
+// the server older than the required version.
+var ErrServerTooOld = errors.New("server version too old")
+
+// Sentinel errors matched by APIError through errors.Is.
+var (
+	// ErrUnauthorized matches API errors with status 401.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound matches API errors with status 404.
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited matches API errors with status 429.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// APIError is returned when the server responds with an error status. Use
+// errors.As to inspect it, or errors.Is with ErrUnauthorized, ErrNotFound or
+// ErrRateLimited to test for common statuses.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Body is the raw response body.
+	Body string
+	// Endpoint is the request method and path, e.g. "POST /api/v1/traces".
+	Endpoint string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Agentreplay API error (%d): %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether target is the sentinel error for e's status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// ValidationError reports an invalid span or option field. It is returned
+// before anything is sent.
+type ValidationError struct {
+	// Field is the name of the offending field, e.g. "SpanID".
+	Field string
+	// Reason says what is wrong with it.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
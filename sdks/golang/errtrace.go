@@ -0,0 +1,63 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+)
+
+// RecordError marks an existing span as failed. It sets the OTel
+// exception.type (the error's Go type), exception.message and
+// exception.stacktrace attributes, and changes the span's type to
+// SpanTypeError. The stack trace is captured where RecordError is called,
+// since Go errors carry none. A nil err is ignored.
+func (c *Client) RecordError(ctx context.Context, edgeID string, err error) error {
+	if err == nil {
+		return nil
+	}
+	attrs := exceptionAttributes(err)
+	attrs["span_type"] = strconv.Itoa(int(SpanTypeError))
+	return c.patchAttributes(ctx, edgeID, attrs)
+}
+
+// RecordError marks the span as failed, with the same attributes as
+// Client.RecordError. Before End it only changes what End will send; after
+// End it patches the sent span through Client.RecordError.
+func (s *Span) RecordError(err error) error {
+	if err == nil {
+		return nil
+	}
+	s.mu.Lock()
+	if !s.ended {
+		s.opts.SpanType = SpanTypeError
+		s.extra = exceptionAttributes(err)
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+	return s.client.RecordError(s.ctx, s.EdgeID, err)
+}
+
+// exceptionAttributes returns the OTel exception attributes for err.
+func exceptionAttributes(err error) map[string]string {
+	return map[string]string{
+		"exception.type":       fmt.Sprintf("%T", err),
+		"exception.message":    err.Error(),
+		"exception.stacktrace": string(debug.Stack()),
+	}
+}
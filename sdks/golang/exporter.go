@@ -0,0 +1,187 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Exporter is the sink for spans produced by the Create* methods, IngestBatch
+// and the async buffer. Spans reach it fully processed: sampled, run through
+// span processors and redacted. Use WithExporter to replace the default,
+// which posts spans to the Agentreplay server, with another transport such as
+// a message queue or a local collector.
+//
+// An Exporter may also implement Flush(ctx context.Context) error and
+// Close() error, which are then called by Client.Flush and Client.Close.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanInput) error
+}
+
+// WithExporter sends spans to exporter instead of the Agentreplay server.
+// Queries and other reads still go to the server. IngestBatch reports every
+// exported span as accepted.
+func WithExporter(exporter Exporter) ClientOption {
+	return func(c *Client) {
+		c.exporter = exporter
+	}
+}
+
+// exportFlusher is implemented by exporters that buffer or cache writes.
+type exportFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// exportCloser is implemented by exporters holding resources.
+type exportCloser interface {
+	Close() error
+}
+
+// ingestExporter is implemented by exporters that return the server's
+// ingestion response.
+type ingestExporter interface {
+	ingest(ctx context.Context, spans []SpanInput) ([]byte, error)
+}
+
+// export hands spans to the exporter and returns the ingestion response
+// body, synthesizing one for exporters that have no server response.
+func (c *Client) export(ctx context.Context, spans []SpanInput) ([]byte, error) {
+	if e, ok := c.exporter.(ingestExporter); ok {
+		return e.ingest(ctx, spans)
+	}
+	if err := c.exporter.Export(ctx, spans); err != nil {
+		return nil, err
+	}
+	return json.Marshal(IngestResponse{Accepted: len(spans)})
+}
+
+// httpExporter posts spans to the server's ingestion endpoint. It is the
+// default Exporter.
+type httpExporter struct {
+	client *Client
+}
+
+// Export implements Exporter.
+func (e *httpExporter) Export(ctx context.Context, spans []SpanInput) error {
+	_, err := e.ingest(ctx, spans)
+	return err
+}
+
+func (e *httpExporter) ingest(ctx context.Context, spans []SpanInput) ([]byte, error) {
+	c := e.client
+	ctx = c.withIdempotencyKey(ctx, spans)
+	if c.useProtobuf(ctx) {
+		body, err := marshalOTLP(spans)
+		if err != nil {
+			return nil, err
+		}
+		return c.doRequest(ctx, "POST", "/api/v1/traces", protobufContentType, body, nil)
+	}
+	return c.request(ctx, "POST", "/api/v1/traces", map[string]interface{}{"spans": spans}, nil)
+}
+
+// WithFileExporter writes spans to the file at path instead of sending them
+// to the server, appending each SpanInput as a line of JSON. The file is
+// created if needed on the first write. It is shorthand for WithExporter with
+// a file sink. Call Flush to sync the file to disk.
+func WithFileExporter(path string) ClientOption {
+	return WithExporter(&fileExporter{path: path})
+}
+
+// fileExporter appends spans to a file as newline-delimited JSON.
+type fileExporter struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Export implements Exporter.
+func (e *fileExporter) Export(ctx context.Context, spans []SpanInput) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.file == nil {
+		f, err := os.OpenFile(e.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open export file: %w", err)
+		}
+		e.file = f
+	}
+
+	var lines []byte
+	for _, span := range spans {
+		b, err := json.Marshal(span)
+		if err != nil {
+			return fmt.Errorf("failed to marshal span: %w", err)
+		}
+		lines = append(append(lines, b...), '\n')
+	}
+	if _, err := e.file.Write(lines); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return nil
+}
+
+// Flush syncs the file to disk.
+func (e *fileExporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return nil
+	}
+	if err := e.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync export file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (e *fileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
+
+// Flush sends every span queued by WithAsyncBuffer and waits until they,
+// and any WithFireAndForget sends in flight, are exported or ctx expires. It
+// then makes sure exported spans are durable, for example by syncing the
+// file written by WithFileExporter to disk. Unlike Close it leaves the
+// client usable, so long-lived services can call it at checkpoints. Buffered
+// spans that fail to send are dropped as usual and their errors returned;
+// fire-and-forget failures are only logged.
+func (c *Client) Flush(ctx context.Context) error {
+	if c.buffer != nil {
+		if err := c.buffer.flush(ctx); err != nil {
+			return err
+		}
+	}
+	if err := c.waitInflight(ctx); err != nil {
+		return err
+	}
+	if f, ok := c.exporter.(exportFlusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
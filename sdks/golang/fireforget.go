@@ -0,0 +1,83 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "context"
+
+// WithFireAndForget makes the Create* methods hand their span to a background
+// goroutine and return at once, for hot paths where losing a trace is
+// preferable to handling an error. The result always carries the generated
+// EdgeID and the returned error is always nil: invalid spans and failed
+// sends are logged through WithLogger and counted through WithMetrics
+// instead. Close and Flush wait for in-flight sends to finish.
+//
+// Unlike WithAsyncBuffer, every span is still sent in its own request; use
+// both to get batching as well.
+func WithFireAndForget() ClientOption {
+	return func(c *Client) {
+		c.fireAndForget = true
+	}
+}
+
+// sendInBackground sends a span on its own goroutine. The send outlives
+// ctx's cancellation, but keeps its values, and is bounded by the client
+// timeout.
+func (c *Client) sendInBackground(ctx context.Context, tenantID int64, span SpanInput) {
+	c.inflight.Add(1)
+	go func() {
+		defer c.inflight.Done()
+		ctx := context.WithoutCancel(ctx)
+		send := func() error {
+			_, err := c.sendSpans(ctx, tenantID, []SpanInput{span})
+			return err
+		}
+		var err error
+		if c.rollup != nil && span.ParentSpanID == nil {
+			err = c.rollup.sendInOrder(span, send)
+		} else {
+			err = send()
+		}
+		if err != nil {
+			c.logger.WarnContext(ctx, "failed to send span", "span_id", span.SpanID, errorAttr(err))
+		}
+	}()
+}
+
+// degrade handles an error that would otherwise be returned from a Create*
+// method. In fire-and-forget mode the span is dropped and the error logged
+// and counted instead.
+func (c *Client) degrade(ctx context.Context, span SpanInput, err error) error {
+	if !c.fireAndForget {
+		return err
+	}
+	c.metrics.IncDropped(1)
+	c.logger.WarnContext(ctx, "dropping span", "span_id", span.SpanID, errorAttr(err))
+	return nil
+}
+
+// waitInflight waits for background sends to finish or ctx to expire.
+func (c *Client) waitInflight(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
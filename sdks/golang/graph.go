@@ -0,0 +1,181 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"strconv"
+	"time"
+)
+
+// GraphExecution describes a completed run of a state-graph agent framework
+// (LangGraph-style): the nodes that executed and the transitions between them.
+type GraphExecution struct {
+	Name      string
+	AgentID   int64
+	SessionID int64
+	Nodes     []GraphNode
+	Edges     []GraphEdge
+}
+
+// GraphNode is a single node execution within a GraphExecution.
+type GraphNode struct {
+	ID       string
+	Name     string
+	SpanType SpanType
+	Start    time.Time
+	End      time.Time
+	Inputs   map[string]interface{}
+	Outputs  map[string]interface{}
+}
+
+// GraphEdge is a transition from one node execution to another.
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// FromStateGraph converts a graph execution into a batch of spans ready for
+// IngestBatch. A root span covers the whole execution. Each node becomes a
+// child of the node that first transitioned into it, and entry nodes, or
+// nodes unreachable from them, hang off the root. Spans are returned with
+// parents before children.
+func FromStateGraph(execution GraphExecution) []SpanInput {
+	used := make(map[string]bool)
+	newEdgeID := func() string {
+		for {
+			id := generateEdgeID()
+			if !used[id] {
+				used[id] = true
+				return id
+			}
+		}
+	}
+
+	traceID := strconv.FormatInt(execution.SessionID, 10)
+	baseAttributes := func(spanType SpanType) map[string]string {
+		return map[string]string{
+			"agent_id":   strconv.FormatInt(execution.AgentID, 10),
+			"session_id": traceID,
+			"span_type":  strconv.Itoa(int(spanType)),
+		}
+	}
+
+	// Root span spanning the earliest start to the latest end.
+	var start, end time.Time
+	for _, node := range execution.Nodes {
+		if start.IsZero() || node.Start.Before(start) {
+			start = node.Start
+		}
+		if node.End.After(end) {
+			end = node.End
+		}
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	name := execution.Name
+	if name == "" {
+		name = "graph"
+	}
+	rootID := newEdgeID()
+	rootEnd := end.UnixMicro()
+	rootAttrs := baseAttributes(SpanTypeRoot)
+	rootAttrs["duration_us"] = strconv.FormatInt(end.Sub(start).Microseconds(), 10)
+	spans := []SpanInput{{
+		SpanID:     rootID,
+		TraceID:    traceID,
+		Name:       name,
+		StartTime:  start.UnixMicro(),
+		EndTime:    &rootEnd,
+		Attributes: rootAttrs,
+	}}
+
+	nodes := make(map[string]GraphNode, len(execution.Nodes))
+	incoming := make(map[string]bool)
+	outgoing := make(map[string][]string)
+	for _, node := range execution.Nodes {
+		nodes[node.ID] = node
+	}
+	for _, edge := range execution.Edges {
+		outgoing[edge.From] = append(outgoing[edge.From], edge.To)
+		incoming[edge.To] = true
+	}
+
+	// Walk breadth-first from the entry nodes so every node is parented by
+	// the first node that reached it, which keeps the result a tree even
+	// when the graph loops.
+	edgeIDs := make(map[string]string, len(execution.Nodes))
+	emit := func(node GraphNode, parentID string) {
+		edgeID := newEdgeID()
+		edgeIDs[node.ID] = edgeID
+		nodeEnd := node.End.UnixMicro()
+		attributes := baseAttributes(node.SpanType)
+		attributes["duration_us"] = strconv.FormatInt(node.End.Sub(node.Start).Microseconds(), 10)
+		attributes["graph.node.id"] = node.ID
+		attributes["graph.node.name"] = node.Name
+		if node.Inputs != nil {
+			attributes["graph.node.input"] = toJSON(node.Inputs)
+		}
+		if node.Outputs != nil {
+			attributes["graph.node.output"] = toJSON(node.Outputs)
+		}
+		parent := parentID
+		spans = append(spans, SpanInput{
+			SpanID:       edgeID,
+			TraceID:      traceID,
+			ParentSpanID: &parent,
+			Name:         node.Name,
+			StartTime:    node.Start.UnixMicro(),
+			EndTime:      &nodeEnd,
+			Attributes:   attributes,
+		})
+	}
+
+	var queue []string
+	drain := func() {
+		for len(queue) > 0 {
+			from := queue[0]
+			queue = queue[1:]
+			for _, to := range outgoing[from] {
+				node, ok := nodes[to]
+				if _, done := edgeIDs[to]; !ok || done {
+					continue
+				}
+				emit(node, edgeIDs[from])
+				queue = append(queue, to)
+			}
+		}
+	}
+	for _, node := range execution.Nodes {
+		if !incoming[node.ID] {
+			emit(node, rootID)
+			queue = append(queue, node.ID)
+		}
+	}
+	drain()
+	for _, node := range execution.Nodes {
+		if _, done := edgeIDs[node.ID]; !done {
+			emit(node, rootID)
+			queue = append(queue, node.ID)
+			drain()
+		}
+	}
+
+	return spans
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithHeaders adds static headers to every request, such as gateway
+// credentials. Calling it again adds to the headers already set.
+// Content-Type and X-Tenant-ID are always set by the SDK and cannot be
+// overridden.
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header, len(headers))
+		}
+		for k, v := range headers {
+			c.headers.Set(k, v)
+		}
+	}
+}
+
+// WithHeaderFunc computes extra headers for each request from its context,
+// for example to mint a fresh token or forward a request ID. It runs on
+// every attempt after the WithHeaders headers are applied, and its values
+// replace static ones with the same name. Content-Type and X-Tenant-ID
+// cannot be overridden.
+func WithHeaderFunc(fn func(ctx context.Context) http.Header) ClientOption {
+	return func(c *Client) {
+		c.headerFunc = fn
+	}
+}
+
+// WithAPIKey authenticates every request with an "Authorization: Bearer"
+// header carrying key. It takes precedence over WithBasicAuth and over any
+// Authorization header from WithHeaders or WithHeaderFunc. The key is kept
+// only in the client and is never included in errors or logs.
+func WithAPIKey(key string) ClientOption {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithBasicAuth authenticates every request with HTTP basic auth. It is
+// ignored when WithAPIKey is also set, and overrides any Authorization header
+// from WithHeaders or WithHeaderFunc. Like the API key, the credentials are
+// never included in errors or logs.
+func WithBasicAuth(user, pass string) ClientOption {
+	return func(c *Client) {
+		c.basicAuthUser = user
+		c.basicAuthPass = pass
+		c.basicAuth = true
+	}
+}
+
+// applyHeaders sets the configured static and dynamic headers and the
+// authentication header on req.
+func (c *Client) applyHeaders(ctx context.Context, req *http.Request) {
+	for k, v := range c.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if c.headerFunc != nil {
+		for k, v := range c.headerFunc(ctx) {
+			req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+
+	switch {
+	case c.apiKey != "":
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	case c.basicAuth:
+		req.SetBasicAuth(c.basicAuthUser, c.basicAuthPass)
+	}
+}
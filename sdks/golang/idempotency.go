@@ -0,0 +1,83 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// idempotencyHeader is the header carrying the idempotency key.
+const idempotencyHeader = "Idempotency-Key"
+
+// WithIdempotency sends an Idempotency-Key header with every ingestion
+// request so the server can discard duplicates, such as a retry of a
+// request that succeeded but whose response was lost (see WithRetry).
+//
+// The key is the same for every attempt of a request and for any later
+// resend of the same spans:
+//
+//   - a single span uses its span ID (the EdgeID), e.g. "1a2b3c4d5e6f7a8b";
+//   - a batch uses "batch-" followed by the hex SHA-256 of its span IDs
+//     joined with newlines, in the order sent.
+//
+// A root span re-sent by WithRollup uses its span ID followed by "-r" and
+// its revision number, both alone and within a batch, so each root update
+// is distinct from the original root and from the others.
+//
+// Servers that do not support the header ignore it.
+func WithIdempotency() ClientOption {
+	return func(c *Client) {
+		c.idempotency = true
+	}
+}
+
+// idempotencyKeyCtx is the context key for the idempotency key of the
+// request being sent.
+type idempotencyKeyCtx struct{}
+
+// withIdempotencyKey attaches the idempotency key for spans to ctx when
+// WithIdempotency is enabled.
+func (c *Client) withIdempotencyKey(ctx context.Context, spans []SpanInput) context.Context {
+	if !c.idempotency || len(spans) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyKeyCtx{}, idempotencyKey(spans))
+}
+
+// idempotencyKey derives the idempotency key for a set of spans.
+func idempotencyKey(spans []SpanInput) string {
+	if len(spans) == 1 {
+		return spanKey(spans[0])
+	}
+	ids := make([]string, len(spans))
+	for i, span := range spans {
+		ids[i] = spanKey(span)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(ids, "\n")))
+	return "batch-" + hex.EncodeToString(sum[:])
+}
+
+// spanKey identifies one send of span: its span ID, plus its revision for
+// WithRollup root updates.
+func spanKey(span SpanInput) string {
+	if span.revision == 0 {
+		return span.SpanID
+	}
+	return span.SpanID + "-r" + strconv.FormatInt(span.revision, 10)
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ImportOptions controls how ImportArchive restores an agent archive.
+type ImportOptions struct {
+	// TenantID, ProjectID and AgentID remap the archived IDs. A zero TenantID
+	// imports into the client's tenant; zero ProjectID and AgentID keep the
+	// archived values.
+	TenantID  int64
+	ProjectID int64
+	AgentID   int64
+	// BatchSize is the number of spans per IngestBatch call. Defaults to 500.
+	BatchSize int
+	// Completed lists sessions already imported by an earlier run, which are
+	// skipped so an interrupted import can resume.
+	Completed map[int64]bool
+	// Checkpoint, if set, is called after each session is fully imported so
+	// the caller can persist progress for a later resume.
+	Checkpoint func(sessionID int64)
+}
+
+// ImportSummary reports the outcome of ImportArchive.
+type ImportSummary struct {
+	SessionsImported int      `json:"sessions_imported"`
+	SessionsSkipped  int      `json:"sessions_skipped"`
+	SessionsFailed   int      `json:"sessions_failed"`
+	SpansImported    int      `json:"spans_imported"`
+	SpansFailed      int      `json:"spans_failed"`
+	FeedbackRestored int      `json:"feedback_restored"`
+	Errors           []string `json:"errors,omitempty"`
+}
+
+// ImportArchive restores an archive written by ExportAgentArchive. Each
+// session's spans are remapped per opts and re-ingested in chunks via
+// IngestBatch, then any feedback recorded in trace metadata is resubmitted.
+// Chunks are therefore validated, split further by WithMaxBatchSize and
+// carry idempotency keys like any other IngestBatch call; as with
+// IngestBatch, sampling does not apply.
+//
+// A failed session is reported in the summary and the import moves on; it is
+// not checkpointed, so it is retried on resume. Chunks of that session that
+// were ingested before the failure are sent again by the retry, so resuming
+// relies on the server deduplicating spans by span ID. Cancelling ctx stops
+// the import and returns the summary so far along with the context error.
+func (c *Client) ImportArchive(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportSummary, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = archivePageSize
+	}
+	tenantID := opts.TenantID
+	if tenantID == 0 {
+		tenantID = c.tenantID
+	}
+
+	summary := &ImportSummary{}
+	tr := tar.NewReader(gz)
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return summary, nil
+		}
+		if err != nil {
+			return summary, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		var sessionID int64
+		if _, err := fmt.Sscanf(header.Name, "sessions/%d.jsonl", &sessionID); err != nil {
+			continue // manifest or unknown entry
+		}
+		if opts.Completed[sessionID] {
+			summary.SessionsSkipped++
+			continue
+		}
+
+		traces, err := readArchiveSession(tr)
+		if err != nil {
+			summary.SessionsFailed++
+			summary.Errors = append(summary.Errors, fmt.Sprintf("session %d: %v", sessionID, err))
+			continue
+		}
+
+		if err := c.importSession(ctx, tenantID, traces, opts, summary); err != nil {
+			summary.SessionsFailed++
+			summary.Errors = append(summary.Errors, fmt.Sprintf("session %d: %v", sessionID, err))
+			if ctx.Err() != nil {
+				return summary, ctx.Err()
+			}
+			continue
+		}
+		summary.SessionsImported++
+		if opts.Checkpoint != nil {
+			opts.Checkpoint(sessionID)
+		}
+	}
+}
+
+// readArchiveSession decodes one session's JSONL entry.
+func readArchiveSession(r io.Reader) ([]TraceView, error) {
+	var traces []TraceView
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var trace TraceView
+		if err := json.Unmarshal(scanner.Bytes(), &trace); err != nil {
+			return nil, fmt.Errorf("failed to decode span: %w", err)
+		}
+		traces = append(traces, trace)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	return traces, nil
+}
+
+// importSession re-ingests one session in chunks and restores its feedback.
+func (c *Client) importSession(ctx context.Context, tenantID int64, traces []TraceView, opts ImportOptions, summary *ImportSummary) error {
+	spans := make([]SpanInput, len(traces))
+	for i, trace := range traces {
+		spans[i] = archivedSpan(trace, tenantID, opts)
+	}
+	spans = orderParentsFirst(spans)
+
+	ctx = context.WithValue(ctx, tenantOverrideKey{}, tenantID)
+	for start := 0; start < len(spans); start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(spans) {
+			end = len(spans)
+		}
+		resp, err := c.IngestBatch(ctx, spans[start:end])
+		if resp != nil {
+			summary.SpansImported += resp.Accepted
+			summary.SpansFailed += resp.Rejected
+		}
+		if err != nil {
+			unsent := len(spans) - end
+			if resp != nil {
+				unsent += end - start - resp.Accepted - resp.Rejected
+			} else {
+				unsent += end - start
+			}
+			summary.SpansFailed += unsent
+			return err
+		}
+	}
+
+	for _, trace := range traces {
+		feedback, ok := trace.Metadata["feedback"].(float64)
+		if !ok {
+			continue
+		}
+		if _, err := c.SubmitFeedback(ctx, trace.EdgeID, int(feedback)); err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("feedback for %s: %v", trace.EdgeID, err))
+			continue
+		}
+		summary.FeedbackRestored++
+	}
+	return nil
+}
+
+// archivedSpan converts an exported trace back into a span for ingestion,
+// applying the ID remapping in opts.
+func archivedSpan(trace TraceView, tenantID int64, opts ImportOptions) SpanInput {
+	projectID := trace.ProjectID
+	if opts.ProjectID != 0 {
+		projectID = opts.ProjectID
+	}
+	agentID := trace.AgentID
+	if opts.AgentID != 0 {
+		agentID = opts.AgentID
+	}
+
+	attributes := make(map[string]string, len(trace.Metadata)+8)
+	for k, v := range trace.Metadata {
+		switch val := v.(type) {
+		case string:
+			attributes[k] = val
+		case float64:
+			attributes[k] = strconv.FormatFloat(val, 'f', -1, 64)
+		case bool:
+			attributes[k] = strconv.FormatBool(val)
+		default:
+			attributes[k] = toJSON(v)
+		}
+	}
+	spanType := trace.SpanType
+	if t, ok := parseSpanType(trace.SpanType); ok {
+		spanType = strconv.Itoa(int(t))
+	}
+	attributes["tenant_id"] = strconv.FormatInt(tenantID, 10)
+	attributes["project_id"] = strconv.FormatInt(projectID, 10)
+	attributes["agent_id"] = strconv.FormatInt(agentID, 10)
+	attributes["session_id"] = strconv.FormatInt(trace.SessionID, 10)
+	attributes["span_type"] = spanType
+	attributes["token_count"] = strconv.Itoa(trace.TokenCount)
+	attributes["duration_us"] = strconv.FormatInt(trace.DurationUs, 10)
+	if trace.Environment != "" {
+		attributes["environment"] = trace.Environment
+	}
+
+	name := trace.Name
+	if name == "" {
+		name = trace.SpanType
+	}
+	var parentSpanID *string
+	if trace.ParentSpanID != "" {
+		parent := trace.ParentSpanID
+		parentSpanID = &parent
+	}
+	endTime := trace.TimestampUs + trace.DurationUs
+
+	return SpanInput{
+		SpanID:       trace.EdgeID,
+		TraceID:      strconv.FormatInt(trace.SessionID, 10),
+		ParentSpanID: parentSpanID,
+		Name:         name,
+		StartTime:    trace.TimestampUs,
+		EndTime:      &endTime,
+		Attributes:   attributes,
+	}
+}
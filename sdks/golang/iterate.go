@@ -0,0 +1,79 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"iter"
+)
+
+// defaultIteratePageSize is the page size IterateTraces uses when the filter
+// sets no Limit.
+const defaultIteratePageSize = 100
+
+// IterateTraces returns an iterator over every trace matching filter,
+// fetching pages with QueryTraces as it goes. filter.Limit sets the page size
+// and filter.Offset the starting point. Each page is fetched once. Iteration
+// ends after Total traces or, when the server reports no Total, after the
+// first page shorter than the page size or an empty one. It also ends on the
+// first error or when ctx is cancelled; the error is yielded as the final
+// element.
+//
+// Example:
+//
+//	for trace, err := range client.IterateTraces(ctx, &agentreplay.QueryFilter{SessionID: &sessionID}) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(trace.EdgeID)
+//	}
+func (c *Client) IterateTraces(ctx context.Context, filter *QueryFilter) iter.Seq2[TraceView, error] {
+	return func(yield func(TraceView, error) bool) {
+		var page QueryFilter
+		if filter != nil {
+			page = *filter
+		}
+		if page.Limit <= 0 {
+			page.Limit = defaultIteratePageSize
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(TraceView{}, err)
+				return
+			}
+			resp, err := c.QueryTraces(ctx, &page)
+			if err != nil {
+				yield(TraceView{}, err)
+				return
+			}
+			for _, trace := range resp.Traces {
+				if !yield(trace, nil) {
+					return
+				}
+			}
+			page.Offset += len(resp.Traces)
+			if len(resp.Traces) == 0 {
+				return
+			}
+			if resp.Total > 0 {
+				if page.Offset >= resp.Total {
+					return
+				}
+			} else if len(resp.Traces) < page.Limit {
+				return
+			}
+		}
+	}
+}
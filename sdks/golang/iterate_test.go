@@ -0,0 +1,78 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newPagingServer starts a server holding n traces that honours limit and
+// offset, reporting Total only when withTotal is set.
+func newPagingServer(t *testing.T, n int, withTotal bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		resp := QueryResponse{Traces: []TraceView{}}
+		for i := offset; i < n && i < offset+limit; i++ {
+			resp.Traces = append(resp.Traces, TraceView{EdgeID: fmt.Sprintf("%x", i)})
+		}
+		if withTotal {
+			resp.Total = n
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIterateTraces(t *testing.T) {
+	tests := []struct {
+		name      string
+		traces    int
+		withTotal bool
+	}{
+		{name: "with total", traces: 250, withTotal: true},
+		{name: "without total", traces: 250},
+		{name: "without total, exact pages", traces: 200},
+		{name: "without total, empty", traces: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(newPagingServer(t, tt.traces, tt.withTotal).URL, 1)
+			defer c.Close()
+
+			seen := 0
+			for trace, err := range c.IterateTraces(context.Background(), &QueryFilter{Limit: 100}) {
+				if err != nil {
+					t.Fatalf("IterateTraces: %v", err)
+				}
+				if want := fmt.Sprintf("%x", seen); trace.EdgeID != want {
+					t.Fatalf("trace %d has EdgeID %q, want %q", seen, trace.EdgeID, want)
+				}
+				seen++
+			}
+			if seen != tt.traces {
+				t.Errorf("iterated %d traces, want %d", seen, tt.traces)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// WithLogger logs the client's own activity to logger: requests and their
+// status codes at debug level, retries and rejected requests at warn level,
+// and failed requests and dropped spans at error or warn level. Entries carry
+// an sdk=agentreplay attribute. Header values, credentials, span attributes
+// and response bodies, which may hold message content, are never logged. By
+// default nothing is logged.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		if logger == nil {
+			c.logger = discardLogger
+			return
+		}
+		c.logger = logger.With("sdk", "agentreplay")
+	}
+}
+
+// discardLogger is the default logger, which drops every record.
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler is a slog.Handler that is never enabled.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// errorAttr describes err for a log entry. API errors are reduced to their
+// status code, since the response body may echo span content.
+func errorAttr(err error) slog.Attr {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return slog.Int("status", apiErr.StatusCode)
+	}
+	return slog.String("error", err.Error())
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "time"
+
+// Metrics receives counters about span submission, for adapting to a
+// metrics library such as prometheus/client_golang. Implementations must be
+// safe for concurrent use.
+type Metrics interface {
+	// IncSent counts spans accepted by the exporter.
+	IncSent(n int)
+	// IncFailed counts spans whose export returned an error.
+	IncFailed(n int)
+	// IncDropped counts spans discarded before export, by span processors
+	// or because the async buffer was full. Sampled-out spans are not
+	// counted.
+	IncDropped(n int)
+	// ObserveLatency records the duration of each HTTP request attempt.
+	ObserveLatency(d time.Duration)
+}
+
+// WithMetrics reports span submission outcomes to metrics.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *Client) {
+		if metrics == nil {
+			metrics = noopMetrics{}
+		}
+		c.metrics = metrics
+	}
+}
+
+// noopMetrics is the default Metrics, which discards everything.
+type noopMetrics struct{}
+
+func (noopMetrics) IncSent(int)                  {}
+func (noopMetrics) IncFailed(int)                {}
+func (noopMetrics) IncDropped(int)               {}
+func (noopMetrics) ObserveLatency(time.Duration) {}
@@ -0,0 +1,60 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxNameLength is the span name length cap used when
+// WithMaxNameLength is not set.
+const defaultMaxNameLength = 256
+
+// WithNameSanitizer replaces the default span name sanitizer, which trims
+// surrounding whitespace. The sanitizer runs on the computed Name of every
+// span produced by the create methods; the length cap from WithMaxNameLength
+// is still applied to its result.
+func WithNameSanitizer(sanitize func(string) string) ClientOption {
+	return func(c *Client) {
+		c.nameSanitizer = sanitize
+	}
+}
+
+// WithMaxNameLength sets the maximum span name length in bytes (default 256).
+// Longer names are truncated on a UTF-8 boundary. A value of zero or less
+// disables the cap.
+func WithMaxNameLength(n int) ClientOption {
+	return func(c *Client) {
+		c.maxNameLength = n
+	}
+}
+
+// sanitizeName applies the configured sanitizer and length cap to a span name.
+func (c *Client) sanitizeName(name string) string {
+	if c.nameSanitizer != nil {
+		name = c.nameSanitizer(name)
+	} else {
+		name = strings.TrimSpace(name)
+	}
+	if c.maxNameLength > 0 && len(name) > c.maxNameLength {
+		cut := c.maxNameLength
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = name[:cut]
+	}
+	return name
+}
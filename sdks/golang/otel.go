@@ -0,0 +1,102 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+// OTelSpanKind mirrors the OpenTelemetry SpanKind enum, using the numeric
+// values of the OTLP protocol.
+type OTelSpanKind int
+
+const (
+	// OTelSpanKindUnspecified is an unset span kind
+	OTelSpanKindUnspecified OTelSpanKind = 0
+	// OTelSpanKindInternal is an internal operation
+	OTelSpanKindInternal OTelSpanKind = 1
+	// OTelSpanKindServer handles an incoming request
+	OTelSpanKindServer OTelSpanKind = 2
+	// OTelSpanKindClient makes an outgoing request
+	OTelSpanKindClient OTelSpanKind = 3
+	// OTelSpanKindProducer enqueues a message
+	OTelSpanKindProducer OTelSpanKind = 4
+	// OTelSpanKindConsumer processes an enqueued message
+	OTelSpanKindConsumer OTelSpanKind = 5
+)
+
+// String returns the string representation of OTelSpanKind.
+func (k OTelSpanKind) String() string {
+	switch k {
+	case OTelSpanKindInternal:
+		return "Internal"
+	case OTelSpanKindServer:
+		return "Server"
+	case OTelSpanKindClient:
+		return "Client"
+	case OTelSpanKindProducer:
+		return "Producer"
+	case OTelSpanKindConsumer:
+		return "Consumer"
+	default:
+		return "Unspecified"
+	}
+}
+
+// OTelKind returns the OpenTelemetry span kind that best matches s. Spans
+// that call out of the process (HTTP, database, tools, model and vector
+// store calls) map to Client, the root maps to Server, and everything else
+// is Internal.
+func (s SpanType) OTelKind() OTelSpanKind {
+	switch s {
+	case SpanTypeRoot:
+		return OTelSpanKindServer
+	case SpanTypeHttpCall, SpanTypeDatabase, SpanTypeToolCall, SpanTypeRetrieval,
+		SpanTypeEmbedding, SpanTypeGeneration:
+		return OTelSpanKindClient
+	default:
+		return OTelSpanKindInternal
+	}
+}
+
+// SpanTypeFromOTel guesses a SpanType for an OpenTelemetry span from its
+// kind and attributes. Well-known semantic-convention attributes take
+// precedence over the kind: gen_ai.*, db.system and http.request.method
+// identify model, database and HTTP calls respectively.
+func SpanTypeFromOTel(kind OTelSpanKind, attributes map[string]string) SpanType {
+	if _, ok := attributes["gen_ai.tool.name"]; ok {
+		return SpanTypeToolCall
+	}
+	if op, ok := attributes["gen_ai.operation.name"]; ok {
+		if op == "embeddings" {
+			return SpanTypeEmbedding
+		}
+		return SpanTypeGeneration
+	}
+	if _, ok := attributes["db.system"]; ok {
+		return SpanTypeDatabase
+	}
+	if _, ok := attributes["http.request.method"]; ok {
+		return SpanTypeHttpCall
+	}
+	if _, ok := attributes["http.method"]; ok {
+		return SpanTypeHttpCall
+	}
+
+	switch kind {
+	case OTelSpanKindServer:
+		return SpanTypeRoot
+	case OTelSpanKindClient:
+		return SpanTypeHttpCall
+	default:
+		return SpanTypeFunction
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Connection pool defaults used for zero TransportConfig fields.
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 50
+	defaultIdleConnTimeout     = 30 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+)
+
+// TransportConfig tunes the connection pool of the client's default HTTP
+// transport. Zero fields keep their defaults; see WithTransportConfig.
+type TransportConfig struct {
+	// MaxIdleConns caps idle connections across all hosts (default 100).
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections to the server (default 50).
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps connections to the server, idle or not. Zero
+	// means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept (default 30s).
+	IdleConnTimeout time.Duration
+	// DialTimeout bounds establishing a TCP connection (default 30s).
+	DialTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period (default 30s).
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake. Zero means no limit.
+	TLSHandshakeTimeout time.Duration
+}
+
+// WithTransportConfig tunes the connection pool of the HTTP transport the
+// client creates for itself. It has no effect together with WithHTTPClient,
+// whichever comes first: a custom client is used as-is, so configure its
+// transport directly instead.
+func WithTransportConfig(config TransportConfig) ClientOption {
+	return func(c *Client) {
+		c.transportConfig = config
+	}
+}
+
+// transport builds an HTTP transport from the config, filling in defaults.
+func (cfg TransportConfig) transport() *http.Transport {
+	if cfg.MaxIdleConns == 0 {
+		cfg.MaxIdleConns = defaultMaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost == 0 {
+		cfg.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout == 0 {
+		cfg.IdleConnTimeout = defaultIdleConnTimeout
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = defaultDialTimeout
+	}
+	if cfg.KeepAlive == 0 {
+		cfg.KeepAlive = defaultKeepAlive
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: cfg.KeepAlive}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		IdleConnTimeout:     cfg.IdleConnTimeout,
+		TLSHandshakeTimeout: cfg.TLSHandshakeTimeout,
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// capabilityOTLPProtobuf is the capability a server advertises in its
+// health response when it accepts OTLP protobuf ingestion.
+const capabilityOTLPProtobuf = "otlp_protobuf"
+
+// protobufContentType is the Content-Type of OTLP protobuf payloads.
+const protobufContentType = "application/x-protobuf"
+
+// WithProtobufEncoding sends ingestion payloads as OTLP protobuf
+// (ExportTraceServiceRequest) instead of JSON. It only takes effect when the
+// server advertises the otlp_protobuf capability in its health response,
+// which is checked on the first send; otherwise JSON is used. If that check
+// fails, the send uses JSON and the check is retried on the next one.
+//
+// The original span, trace and parent IDs are carried in agentreplay.*
+// attributes, since OTLP requires fixed-width binary IDs.
+func WithProtobufEncoding(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.protobuf = enabled
+	}
+}
+
+// useProtobuf reports whether ingestion should be protobuf-encoded,
+// negotiating the capability with the server on first use. A failed probe
+// is not cached: the request falls back to JSON and the next one probes
+// again.
+func (c *Client) useProtobuf(ctx context.Context) bool {
+	if !c.protobuf {
+		return false
+	}
+	c.protobufMu.Lock()
+	defer c.protobufMu.Unlock()
+	if c.protobufChecked {
+		return c.protobufSupported
+	}
+
+	health, err := c.Health(ctx)
+	if err != nil {
+		c.logger.WarnContext(ctx, "failed to negotiate protobuf encoding, sending JSON", errorAttr(err))
+		return false
+	}
+	c.protobufChecked = true
+	for _, capability := range health.Capabilities {
+		if capability == capabilityOTLPProtobuf {
+			c.protobufSupported = true
+			break
+		}
+	}
+	return c.protobufSupported
+}
+
+// marshalOTLP encodes spans as an OTLP ExportTraceServiceRequest. TracesData
+// has the same wire format and avoids pulling in the gRPC collector stubs.
+func marshalOTLP(spans []SpanInput) ([]byte, error) {
+	otlpSpans := make([]*tracepb.Span, len(spans))
+	for i, span := range spans {
+		otlpSpans[i] = toOTLPSpan(span)
+	}
+
+	req := &tracepb.TracesData{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			ScopeSpans: []*tracepb.ScopeSpans{{
+				Scope: &commonpb.InstrumentationScope{Name: "agentreplay-go"},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OTLP request: %w", err)
+	}
+	return b, nil
+}
+
+// toOTLPSpan converts a span into its OTLP form.
+func toOTLPSpan(span SpanInput) *tracepb.Span {
+	attributes := make([]*commonpb.KeyValue, 0, len(span.Attributes)+3)
+	for k, v := range span.Attributes {
+		attributes = append(attributes, stringKeyValue(k, v))
+	}
+	attributes = append(attributes,
+		stringKeyValue("agentreplay.span_id", span.SpanID),
+		stringKeyValue("agentreplay.trace_id", span.TraceID),
+	)
+
+	out := &tracepb.Span{
+		TraceId:           otlpTraceID(span.TraceID),
+		SpanId:            otlpSpanID(span.SpanID),
+		Name:              span.Name,
+		StartTimeUnixNano: uint64(span.StartTime) * 1000,
+		Attributes:        attributes,
+	}
+	if span.EndTime != nil {
+		out.EndTimeUnixNano = uint64(*span.EndTime) * 1000
+	}
+	if span.ParentSpanID != nil {
+		out.ParentSpanId = otlpSpanID(*span.ParentSpanID)
+		out.Attributes = append(out.Attributes, stringKeyValue("agentreplay.parent_span_id", *span.ParentSpanID))
+	}
+	if spanType, err := strconv.Atoi(span.Attributes["span_type"]); err == nil {
+		out.Kind = tracepb.Span_SpanKind(SpanType(spanType).OTelKind())
+	}
+	for _, event := range span.Events {
+		eventAttrs := make([]*commonpb.KeyValue, 0, len(event.Attributes))
+		for k, v := range event.Attributes {
+			eventAttrs = append(eventAttrs, stringKeyValue(k, v))
+		}
+		out.Events = append(out.Events, &tracepb.Span_Event{
+			Name:         event.Name,
+			TimeUnixNano: uint64(event.Timestamp) * 1000,
+			Attributes:   eventAttrs,
+		})
+	}
+	return out
+}
+
+// otlpTraceID maps a trace ID to 16 bytes. Numeric session IDs are stored
+// big-endian in the low 8 bytes, 32-character hex IDs are decoded as-is, and
+// anything else is hashed.
+func otlpTraceID(traceID string) []byte {
+	id := make([]byte, 16)
+	if n, err := strconv.ParseInt(traceID, 10, 64); err == nil {
+		binary.BigEndian.PutUint64(id[8:], uint64(n))
+		return id
+	}
+	if b, err := hex.DecodeString(traceID); err == nil && len(b) == 16 {
+		return b
+	}
+	sum := sha256.Sum256([]byte(traceID))
+	copy(id, sum[:16])
+	return id
+}
+
+// otlpSpanID maps an edge ID to 8 bytes. Hex edge IDs are decoded directly,
+// anything else (such as UpdateTrace's "_complete" IDs) is hashed.
+func otlpSpanID(edgeID string) []byte {
+	id := make([]byte, 8)
+	if n, err := strconv.ParseUint(edgeID, 16, 64); err == nil {
+		binary.BigEndian.PutUint64(id, n)
+		return id
+	}
+	sum := sha256.Sum256([]byte(edgeID))
+	copy(id, sum[:8])
+	return id
+}
+
+// stringKeyValue builds a string-valued OTLP attribute.
+func stringKeyValue(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps how fast the client sends requests to the server, using
+// a token bucket that refills at rps requests per second and holds up to
+// burst tokens. Every request attempt, retries included, takes a token and
+// waits for one when the bucket is empty, giving up if its context ends
+// first. A non-positive rps disables the limit.
+//
+// Pair it with WithRetry to absorb the occasional 429 when the server quota
+// is lower than the configured rate. Metrics implementations that also
+// implement RateLimitObserver are told how long each delayed request waited.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = &rateLimiter{
+			rate:   rps,
+			burst:  float64(burst),
+			tokens: float64(burst),
+		}
+	}
+}
+
+// RateLimitObserver is an optional extension of Metrics. When the Metrics
+// passed to WithMetrics implements it, ObserveRateLimitDelay is called for
+// every request WithRateLimit held back, with the time it waited.
+type RateLimitObserver interface {
+	ObserveRateLimitDelay(d time.Duration)
+}
+
+// rateLimiter is a token bucket. Tokens are taken up front, so concurrent
+// callers queue behind each other instead of racing for the next token.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// refund returns a token taken by a caller that gave up waiting.
+func (l *rateLimiter) refund() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// wait blocks until a token is available or ctx ends, and returns how long
+// it waited.
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	delay := l.reserve()
+	if delay <= 0 {
+		return 0, nil
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		l.refund()
+		return delay, err
+	}
+	return delay, nil
+}
+
+// waitRateLimit applies the WithRateLimit limit to one request attempt.
+func (c *Client) waitRateLimit(ctx context.Context, method, path string) error {
+	if c.limiter == nil {
+		return nil
+	}
+	delay, err := c.limiter.wait(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for rate limit: %w", err)
+	}
+	if delay > 0 {
+		c.logger.DebugContext(ctx, "request delayed by rate limit", "method", method, "path", path, "delay", delay)
+		if observer, ok := c.metrics.(RateLimitObserver); ok {
+			observer.ObserveRateLimitDelay(delay)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// WithRedactor runs redact over every span attribute, including span event
+// attributes, just before spans are sent, replacing each value with the
+// result. For gen_ai.prompt.messages and gen_ai.completion.message it is
+// applied to each message's content rather than to the encoded JSON.
+//
+// Spans flagged through the Sensitivity option are handled more strictly
+// first: the prompt, completion, tool, retrieval and reasoning content of
+// PII and Secret spans is replaced wholesale with [REDACTED_PII] or
+// [REDACTED_SECRET]. NoEmbed spans are sent as-is; the server honours the
+// flag by keeping them out of its vector index.
+//
+// Redaction runs after span processors, so nothing they add escapes it.
+// DefaultRedactor covers emails, phone numbers and card numbers.
+func WithRedactor(redact func(attrKey, attrValue string) string) ClientOption {
+	return func(c *Client) {
+		c.redactor = redact
+	}
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[-. ]?)?(?:\(\d{3}\)|\d{3})[-. ]\d{3}[-. ]\d{4}\b|\+\d{10,14}\b`)
+)
+
+// identifierAttributes are the SDK's own numeric attributes, which
+// DefaultRedactor leaves alone so IDs are never mistaken for card or phone
+// numbers.
+var identifierAttributes = map[string]bool{
+	"tenant_id":   true,
+	"project_id":  true,
+	"agent_id":    true,
+	"session_id":  true,
+	"span_type":   true,
+	"token_count": true,
+	"duration_us": true,
+	"sensitivity": true,
+}
+
+// DefaultRedactor replaces email addresses, phone numbers and card numbers
+// that pass the Luhn check with [REDACTED_EMAIL], [REDACTED_PHONE] and
+// [REDACTED_CARD]. Use it with WithRedactor.
+func DefaultRedactor(attrKey, attrValue string) string {
+	if identifierAttributes[attrKey] {
+		return attrValue
+	}
+	attrValue = emailPattern.ReplaceAllString(attrValue, "[REDACTED_EMAIL]")
+	attrValue = cardPattern.ReplaceAllStringFunc(attrValue, func(match string) string {
+		if luhnValid(match) {
+			return "[REDACTED_CARD]"
+		}
+		return match
+	})
+	return phonePattern.ReplaceAllString(attrValue, "[REDACTED_PHONE]")
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactSpans returns copies of spans with the redactor applied.
+func (c *Client) redactSpans(spans []SpanInput) []SpanInput {
+	out := make([]SpanInput, len(spans))
+	for i, span := range spans {
+		span.Attributes = c.redactAttributes(applySensitivityRedaction(span.Attributes))
+		if len(span.Events) > 0 {
+			events := make([]SpanEvent, len(span.Events))
+			for j, event := range span.Events {
+				event.Attributes = c.redactAttributes(event.Attributes)
+				events[j] = event
+			}
+			span.Events = events
+		}
+		out[i] = span
+	}
+	return out
+}
+
+// redactAttributes returns a redacted copy of attributes.
+func (c *Client) redactAttributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+	out := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		switch k {
+		case "gen_ai.prompt.messages", "gen_ai.completion.message":
+			out[k] = c.redactMessages(k, v)
+		default:
+			out[k] = c.redactor(k, v)
+		}
+	}
+	return out
+}
+
+// redactMessages applies the redactor to the content of the JSON-encoded
+// message or message list in value: string content, the text of content
+// parts, and tool call arguments. Values that are not valid message JSON
+// are redacted as plain strings.
+func (c *Client) redactMessages(key, value string) string {
+	redactField := func(obj interface{}, field string) {
+		if m, ok := obj.(map[string]interface{}); ok {
+			if text, ok := m[field].(string); ok {
+				m[field] = c.redactor(key, text)
+			}
+		}
+	}
+	redact := func(message map[string]interface{}) {
+		switch content := message["content"].(type) {
+		case string:
+			message["content"] = c.redactor(key, content)
+		case []interface{}:
+			for _, part := range content {
+				redactField(part, "text")
+			}
+		}
+		if calls, ok := message["tool_calls"].([]interface{}); ok {
+			for _, call := range calls {
+				redactField(call, "arguments")
+			}
+		}
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") {
+		var messages []map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &messages); err == nil {
+			for _, message := range messages {
+				redact(message)
+			}
+			return toJSON(messages)
+		}
+	} else {
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &message); err == nil {
+			redact(message)
+			return toJSON(message)
+		}
+	}
+	return c.redactor(key, value)
+}
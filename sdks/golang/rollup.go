@@ -0,0 +1,239 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"sync"
+)
+
+// rollupCacheSize bounds how many sessions WithRollup tracks at once.
+const rollupCacheSize = 10000
+
+// WithRollup keeps each session's root span up to date with its children.
+// Every time a child span is sent, the root's token_count becomes its own
+// plus the sum of its children's (token_count, or GenAI input plus output
+// usage), its end time moves out to the latest child end time, and the
+// updated root is re-sent under the same span ID, so servers that keep the
+// latest write for a span show accurate totals.
+//
+// With WithIdempotency, each re-send carries its own Idempotency-Key, the
+// root's span ID followed by "-r" and a per-session revision number (e.g.
+// "1a2b3c4d5e6f7a8b-r3"), so a deduplicating server does not discard root
+// updates as repeats of the original root.
+//
+// The root is the last span without a ParentSpanID sent through this client
+// for the session; children of sessions whose root was never seen are left
+// alone. Re-sending a child replaces its earlier token count rather than
+// adding to it. Root updates for a session are built one at a time under a
+// per-session lock, so concurrent children never lose each other's totals,
+// and each carries a revision number. A WithAsyncBuffer batch keeps only
+// the newest revision of each root, and with WithFireAndForget a revision
+// older than one already sent is skipped, so the last root written always
+// carries the latest totals. At most 10000 sessions are tracked, evicting
+// the oldest first.
+func WithRollup() ClientOption {
+	return func(c *Client) {
+		c.rollup = newRollupTracker(rollupCacheSize)
+	}
+}
+
+// rollupTracker holds the per-session aggregates for WithRollup.
+type rollupTracker struct {
+	mu       sync.Mutex
+	sessions map[string]*rollupSession
+	order    []string
+	next     int
+}
+
+// rollupSession aggregates the children of one session's root span.
+type rollupSession struct {
+	mu          sync.Mutex
+	root        SpanInput
+	rootTokens  int64
+	childTokens map[string]int64
+	total       int64
+	end         int64
+	revision    int64
+
+	// sendMu orders fire-and-forget sends of the root and its updates; sent
+	// is the newest revision sent so far, or -1 before the first.
+	sendMu sync.Mutex
+	sent   int64
+}
+
+func newRollupTracker(size int) *rollupTracker {
+	return &rollupTracker{
+		sessions: make(map[string]*rollupSession, size),
+		order:    make([]string, 0, size),
+	}
+}
+
+// track starts a new aggregate for root, replacing any earlier one for
+// its session.
+func (r *rollupTracker) track(sessionID string, root SpanInput) {
+	root.Attributes = maps.Clone(root.Attributes)
+	s := &rollupSession{
+		root:        root,
+		rootTokens:  spanTokens(root.Attributes),
+		childTokens: make(map[string]int64),
+		end:         spanEnd(root),
+		sent:        -1,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[sessionID]; !ok {
+		if len(r.order) < cap(r.order) {
+			r.order = append(r.order, sessionID)
+		} else {
+			delete(r.sessions, r.order[r.next])
+			r.order[r.next] = sessionID
+			r.next = (r.next + 1) % len(r.order)
+		}
+	}
+	r.sessions[sessionID] = s
+}
+
+// observe records span. A root starts a new aggregate; a child updates its
+// session's aggregate and passes the updated root to emit, holding the
+// session's lock so revisions are numbered and handed off in order.
+func (r *rollupTracker) observe(span SpanInput, emit func(SpanInput) error) error {
+	sessionID := span.Attributes["session_id"]
+	if span.ParentSpanID == nil {
+		r.track(sessionID, span)
+		return nil
+	}
+
+	r.mu.Lock()
+	s := r.sessions[sessionID]
+	r.mu.Unlock()
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens := spanTokens(span.Attributes)
+	s.total += tokens - s.childTokens[span.SpanID]
+	s.childTokens[span.SpanID] = tokens
+	if end := spanEnd(span); end > s.end {
+		s.end = end
+	}
+
+	root := s.root
+	root.Attributes = maps.Clone(s.root.Attributes)
+	root.Attributes["token_count"] = strconv.FormatInt(s.rootTokens+s.total, 10)
+	root.Attributes["duration_us"] = strconv.FormatInt(s.end-root.StartTime, 10)
+	end := s.end
+	root.EndTime = &end
+	s.revision++
+	root.revision = s.revision
+	return emit(root)
+}
+
+// sendInOrder calls send for a root or root update unless a newer revision
+// of the same root has already been sent, serializing the sends of each session's
+// root so they reach the server in revision order.
+func (r *rollupTracker) sendInOrder(root SpanInput, send func() error) error {
+	r.mu.Lock()
+	s := r.sessions[root.Attributes["session_id"]]
+	r.mu.Unlock()
+	if s == nil || s.root.SpanID != root.SpanID {
+		return send()
+	}
+
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if root.revision <= s.sent {
+		return nil
+	}
+	if err := send(); err != nil {
+		return err
+	}
+	s.sent = root.revision
+	return nil
+}
+
+// latestRevisions returns spans with every WithRollup root update except the
+// newest revision of each root removed, so a batch cannot carry an older
+// revision past a newer one. The newest copy takes the place of the first.
+func latestRevisions(spans []SpanInput) []SpanInput {
+	newest := make(map[string]int)
+	for i, span := range spans {
+		if span.revision == 0 {
+			continue
+		}
+		if j, ok := newest[span.SpanID]; !ok || span.revision > spans[j].revision {
+			newest[span.SpanID] = i
+		}
+	}
+	if len(newest) == 0 {
+		return spans
+	}
+
+	out := make([]SpanInput, 0, len(spans))
+	placed := make(map[string]bool, len(newest))
+	for _, span := range spans {
+		j, ok := newest[span.SpanID]
+		if !ok {
+			out = append(out, span)
+			continue
+		}
+		if !placed[span.SpanID] {
+			placed[span.SpanID] = true
+			out = append(out, spans[j])
+		}
+	}
+	return out
+}
+
+// rollupSpan feeds span to the WithRollup tracker, sending any updated root
+// the same way span was sent. A failed root update is logged rather than
+// returned, since span itself went out.
+func (c *Client) rollupSpan(ctx context.Context, tenantID int64, span SpanInput) {
+	err := c.rollup.observe(span, func(root SpanInput) error {
+		return c.dispatch(ctx, tenantID, root)
+	})
+	if err != nil {
+		c.logger.WarnContext(ctx, "failed to send rolled-up root span", "span_id", span.SpanID, errorAttr(err))
+	}
+}
+
+// spanEnd returns when span ended, falling back to its start time.
+func spanEnd(span SpanInput) int64 {
+	if span.EndTime != nil {
+		return *span.EndTime
+	}
+	return span.StartTime
+}
+
+// spanTokens returns a span's token count: token_count when set, otherwise
+// the sum of its GenAI input and output usage.
+func spanTokens(attributes map[string]string) int64 {
+	if _, ok := attributes["token_count"]; ok {
+		return attributeInt(attributes, "token_count")
+	}
+	return attributeInt(attributes, "gen_ai.usage.input_tokens") + attributeInt(attributes, "gen_ai.usage.output_tokens")
+}
+
+// attributeInt parses an integer attribute, returning 0 if it is missing or
+// malformed.
+func attributeInt(attributes map[string]string, key string) int64 {
+	n, _ := strconv.ParseInt(attributes[key], 10, 64)
+	return n
+}
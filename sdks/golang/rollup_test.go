@@ -0,0 +1,94 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRollupBufferedSendsLatestRoot(t *testing.T) {
+	var mu sync.Mutex
+	var received []SpanInput
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Spans []SpanInput `json:"spans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, payload.Spans...)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(IngestResponse{Accepted: len(payload.Spans)})
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, 1, WithRollup(), WithAsyncBuffer(100, time.Hour))
+	defer c.Close()
+	ctx := context.Background()
+
+	root, err := c.CreateTrace(ctx, CreateTraceOptions{SessionID: 42})
+	if err != nil {
+		t.Fatalf("CreateTrace: %v", err)
+	}
+	// Each child queues a root update right behind it, so the batch
+	// interleaves children with successive revisions of the root.
+	const children = 4
+	for i := 1; i <= children; i++ {
+		_, err := c.CreateGenAITrace(ctx, CreateGenAITraceOptions{
+			SessionID: 42,
+			ParentID:  root.EdgeID,
+			Model:     "gpt-4o",
+			Usage:     &Usage{InputTokens: 10 * i, OutputTokens: i, TotalTokens: 11 * i},
+		})
+		if err != nil {
+			t.Fatalf("CreateGenAITrace %d: %v", i, err)
+		}
+	}
+	if err := c.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var last *SpanInput
+	copies := 0
+	for i := range received {
+		if received[i].SpanID == root.EdgeID {
+			last = &received[i]
+			copies++
+		}
+	}
+	if last == nil {
+		t.Fatal("root span was never sent")
+	}
+	if copies != 1 {
+		t.Errorf("root sent %d times in one batch, want 1", copies)
+	}
+	const want = "110" // 11 * (1 + 2 + 3 + 4)
+	if got := last.Attributes["token_count"]; got != want {
+		t.Errorf("last root token_count = %q, want %q", got, want)
+	}
+	if len(received) != children+1 {
+		t.Errorf("received %d spans, want %d", len(received), children+1)
+	}
+}
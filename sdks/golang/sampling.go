@@ -0,0 +1,183 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// WithSamplingPerType sets per-span-type sampling rates between 0 and 1.
+// Types missing from rates use the default rate (see WithDefaultSampleRate).
+// Root and Error spans are always kept. CreateGenAITrace spans are sampled
+// under SpanTypeGeneration. A dropped span is never sent, but its Create*
+// call still returns a result with the generated EdgeID.
+//
+// Example:
+//
+//	agentreplay.WithSamplingPerType(map[agentreplay.SpanType]float64{
+//	    agentreplay.SpanTypeGeneration: 0.1,
+//	    agentreplay.SpanTypeToolCall:   1,
+//	})
+func WithSamplingPerType(rates map[SpanType]float64) ClientOption {
+	return func(c *Client) {
+		c.sampleRates = make(map[SpanType]float64, len(rates))
+		for t, rate := range rates {
+			c.sampleRates[t] = rate
+		}
+	}
+}
+
+// WithDefaultSampleRate sets the sampling rate for span types that have no
+// entry in WithSamplingPerType. Defaults to 1 (keep everything).
+func WithDefaultSampleRate(rate float64) ClientOption {
+	return func(c *Client) {
+		c.defaultSampleRate = rate
+	}
+}
+
+// sampleSpanType reports whether a span of the given type should be sent.
+func (c *Client) sampleSpanType(spanType SpanType) bool {
+	if spanType == SpanTypeRoot || spanType == SpanTypeError {
+		return true
+	}
+	rate, ok := c.sampleRates[spanType]
+	if !ok {
+		rate = c.defaultSampleRate
+	}
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return rand.Float64() < rate
+}
+
+// Sampler decides whether a session is recorded. The decision is made once,
+// for the first span created in the session (normally its root), and reused
+// for every later span, so a session is either recorded whole or not at all.
+type Sampler interface {
+	ShouldSample(sessionID int64) bool
+}
+
+// SamplerFunc adapts a function to the Sampler interface.
+type SamplerFunc func(sessionID int64) bool
+
+// ShouldSample implements Sampler.
+func (f SamplerFunc) ShouldSample(sessionID int64) bool {
+	return f(sessionID)
+}
+
+// AlwaysSample returns a Sampler that records every session.
+func AlwaysSample() Sampler {
+	return SamplerFunc(func(int64) bool { return true })
+}
+
+// NeverSample returns a Sampler that drops every session.
+func NeverSample() Sampler {
+	return SamplerFunc(func(int64) bool { return false })
+}
+
+// RatioSampler returns a Sampler that records the given fraction of
+// sessions. The decision is derived from a hash of the session ID, so a
+// session gets the same answer from every client, clones and separate
+// processes included, and however long it runs.
+func RatioSampler(fraction float64) Sampler {
+	return SamplerFunc(func(sessionID int64) bool {
+		if fraction >= 1 {
+			return true
+		}
+		if fraction <= 0 {
+			return false
+		}
+		return sessionHash(sessionID) < uint64(fraction*math.MaxUint64)
+	})
+}
+
+// sessionHash is the FNV-64a hash of sessionID's little-endian bytes. The
+// low-order bytes, which differ most between sequential IDs, go in first so
+// they are mixed into the high bits that RatioSampler compares.
+func sessionHash(sessionID int64) uint64 {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(sessionID))
+	h := fnv.New64a()
+	h.Write(b[:])
+	return h.Sum64()
+}
+
+// WithSampler sets a per-session sampler. It is applied before
+// WithSamplingPerType, which can still drop individual spans of a recorded
+// session. Unlike per-type sampling, a dropped session loses its Root and
+// Error spans too. Decisions are cached for the most recent sessions; a
+// session evicted from the cache is decided afresh, which only matters for
+// samplers that, unlike RatioSampler, do not derive the decision from the
+// session ID.
+func WithSampler(sampler Sampler) ClientOption {
+	return func(c *Client) {
+		c.sampler = sampler
+	}
+}
+
+// sessionSampleCacheSize bounds how many session decisions are remembered.
+const sessionSampleCacheSize = 10000
+
+// sampleSession reports whether spans in sessionID should be sent, deciding
+// and caching on first use.
+func (c *Client) sampleSession(sessionID int64) bool {
+	if c.sampler == nil {
+		return true
+	}
+	return c.sessionSamples.decide(sessionID, c.sampler)
+}
+
+// sessionSampleCache remembers sampling decisions for a bounded number of
+// sessions, evicting the oldest first.
+type sessionSampleCache struct {
+	mu        sync.Mutex
+	decisions map[int64]bool
+	order     []int64
+	next      int
+}
+
+func newSessionSampleCache(size int) *sessionSampleCache {
+	return &sessionSampleCache{
+		decisions: make(map[int64]bool, size),
+		order:     make([]int64, 0, size),
+	}
+}
+
+// decide returns the cached decision for sessionID, asking sampler when
+// there is none.
+func (s *sessionSampleCache) decide(sessionID int64, sampler Sampler) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if keep, ok := s.decisions[sessionID]; ok {
+		return keep
+	}
+	keep := sampler.ShouldSample(sessionID)
+	if len(s.order) < cap(s.order) {
+		s.order = append(s.order, sessionID)
+	} else {
+		delete(s.decisions, s.order[s.next])
+		s.order[s.next] = sessionID
+		s.next = (s.next + 1) % len(s.order)
+	}
+	s.decisions[sessionID] = keep
+	return keep
+}
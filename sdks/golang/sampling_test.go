@@ -0,0 +1,37 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "testing"
+
+func TestRatioSamplerDeterministic(t *testing.T) {
+	const sessions = 100000
+	for _, fraction := range []float64{0.01, 0.1, 0.5, 0.9} {
+		a, b := RatioSampler(fraction), RatioSampler(fraction)
+		kept := 0
+		for id := int64(1); id <= sessions; id++ {
+			keep := a.ShouldSample(id)
+			if keep != b.ShouldSample(id) || keep != a.ShouldSample(id) {
+				t.Fatalf("RatioSampler(%v) gave session %d different decisions", fraction, id)
+			}
+			if keep {
+				kept++
+			}
+		}
+		if got := float64(kept) / sessions; got < fraction*0.9 || got > fraction*1.1 {
+			t.Errorf("RatioSampler(%v) kept %.4f of sequential sessions", fraction, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sensitivityNames names each flag for SensitivityFlags.String, in bit order.
+var sensitivityNames = []struct {
+	flag SensitivityFlags
+	name string
+}{
+	{SensitivityPII, "pii"},
+	{SensitivitySecret, "secret"},
+	{SensitivityInternal, "internal"},
+	{SensitivityNoEmbed, "no_embed"},
+}
+
+// Has reports whether every bit of flag is set in f.
+func (f SensitivityFlags) Has(flag SensitivityFlags) bool {
+	return f&flag == flag
+}
+
+// String returns the set flags joined with "|", such as "pii|no_embed", or
+// "none" when no flag is set.
+func (f SensitivityFlags) String() string {
+	if f == SensitivityNone {
+		return "none"
+	}
+	var names []string
+	rest := f
+	for _, n := range sensitivityNames {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+			rest &^= n.flag
+		}
+	}
+	if rest != 0 {
+		names = append(names, "0x"+strconv.FormatUint(uint64(rest), 16))
+	}
+	return strings.Join(names, "|")
+}
+
+// applySensitivity writes the sensitivity bitmask as the sensitivity
+// attribute, in the numeric form the server stores.
+func applySensitivity(attributes map[string]string, flags SensitivityFlags) {
+	if flags != SensitivityNone {
+		attributes["sensitivity"] = strconv.Itoa(int(flags))
+	}
+}
+
+// spanSensitivity reads the sensitivity attribute back.
+func spanSensitivity(attributes map[string]string) SensitivityFlags {
+	n, err := strconv.ParseUint(attributes["sensitivity"], 10, 8)
+	if err != nil {
+		return SensitivityNone
+	}
+	return SensitivityFlags(n)
+}
+
+// contentAttributes are the attributes that carry prompt, completion, tool
+// and retrieval content, as opposed to identifiers, timing and usage.
+var contentAttributes = map[string]bool{
+	"gen_ai.prompt.messages":    true,
+	"gen_ai.completion.message": true,
+	"gen_ai.prompt.variables":   true,
+	"gen_ai.tool.call.input":    true,
+	"gen_ai.tool.call.output":   true,
+	"retrieval.query":           true,
+	"retrieval.documents":       true,
+	"decision.reason":           true,
+	"reasoning.content":         true,
+}
+
+// applySensitivityRedaction enforces a span's sensitivity flags on its
+// content attributes, returning the attributes left for the regular
+// redactor: content of PII or Secret spans is replaced wholesale instead of
+// pattern-matched. SensitivityNoEmbed is left to the server, which reads it
+// from the sensitivity attribute and keeps the span out of its vector index.
+func applySensitivityRedaction(attributes map[string]string) map[string]string {
+	flags := spanSensitivity(attributes)
+	var placeholder string
+	switch {
+	case flags.Has(SensitivitySecret):
+		placeholder = "[REDACTED_SECRET]"
+	case flags.Has(SensitivityPII):
+		placeholder = "[REDACTED_PII]"
+	default:
+		return attributes
+	}
+
+	out := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		if contentAttributes[k] {
+			v = placeholder
+		}
+		out[k] = v
+	}
+	return out
+}
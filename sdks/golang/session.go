@@ -0,0 +1,210 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Session is a handle for a single agent run. Its Create* methods record
+// spans under the session's SessionID, overriding any SessionID in the
+// options, so callers don't have to thread the ID through every call.
+//
+// Example:
+//
+//	session := client.NewSession()
+//	root, err := session.CreateTrace(ctx, agentreplay.CreateTraceOptions{SpanType: agentreplay.SpanTypeRoot})
+//	_, err = session.CreateGenAI(ctx, agentreplay.CreateGenAITraceOptions{ParentID: root.EdgeID, Model: "gpt-4o"})
+//	tree, err := session.Tree(ctx)
+type Session struct {
+	SessionID int64
+	client    *Client
+}
+
+// NewSession starts a session with a fresh auto-generated ID, taken from
+// the same counter used when a Create* call leaves SessionID at zero.
+func (c *Client) NewSession() *Session {
+	return &Session{SessionID: c.nextSessionID(), client: c}
+}
+
+// CreateTrace records a span in the session, like Client.CreateTrace.
+func (s *Session) CreateTrace(ctx context.Context, opts CreateTraceOptions) (*TraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateTrace(ctx, opts)
+}
+
+// CreateGenAI records an LLM call in the session, like Client.CreateGenAITrace.
+func (s *Session) CreateGenAI(ctx context.Context, opts CreateGenAITraceOptions) (*GenAITraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateGenAITrace(ctx, opts)
+}
+
+// CreateTool records a tool call in the session, like Client.CreateToolTrace.
+func (s *Session) CreateTool(ctx context.Context, opts CreateToolTraceOptions) (*ToolTraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateToolTrace(ctx, opts)
+}
+
+// CreateDecision records a decision in the session, like Client.CreateDecisionTrace.
+func (s *Session) CreateDecision(ctx context.Context, opts CreateDecisionTraceOptions) (*DecisionTraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateDecisionTrace(ctx, opts)
+}
+
+// CreateReasoning records a reasoning step in the session, like Client.CreateReasoningTrace.
+func (s *Session) CreateReasoning(ctx context.Context, opts CreateReasoningTraceOptions) (*ReasoningTraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateReasoningTrace(ctx, opts)
+}
+
+// CreateGuardrail records a guardrail check in the session, like Client.CreateGuardrailTrace.
+func (s *Session) CreateGuardrail(ctx context.Context, opts CreateGuardrailTraceOptions) (*GuardrailTraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateGuardrailTrace(ctx, opts)
+}
+
+// CreateEmbedding records an embedding call in the session, like Client.CreateEmbeddingTrace.
+func (s *Session) CreateEmbedding(ctx context.Context, opts CreateEmbeddingTraceOptions) (*EmbeddingTraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateEmbeddingTrace(ctx, opts)
+}
+
+// CreateRetrieval records a retrieval in the session, like Client.CreateRetrievalTrace.
+func (s *Session) CreateRetrieval(ctx context.Context, opts CreateRetrievalTraceOptions) (*RetrievalTraceResult, error) {
+	opts.SessionID = s.SessionID
+	return s.client.CreateRetrievalTrace(ctx, opts)
+}
+
+// Tree fetches the session's spans as a forest, like Client.GetSessionTree.
+func (s *Session) Tree(ctx context.Context) ([]TraceTreeNode, error) {
+	return s.client.GetSessionTree(ctx, s.SessionID)
+}
+
+// SessionWallClock returns the wall-clock window covered by a session's
+// spans: the earliest start to the latest start+duration. Unlike summing span
+// durations, this doesn't over-count spans that ran in parallel. It returns
+// zero values when traces is empty.
+func SessionWallClock(traces []TraceView) (start, end time.Time, duration time.Duration) {
+	if len(traces) == 0 {
+		return time.Time{}, time.Time{}, 0
+	}
+
+	startUs := traces[0].TimestampUs
+	endUs := traces[0].TimestampUs + traces[0].DurationUs
+	for _, trace := range traces[1:] {
+		if trace.TimestampUs < startUs {
+			startUs = trace.TimestampUs
+		}
+		if e := trace.TimestampUs + trace.DurationUs; e > endUs {
+			endUs = e
+		}
+	}
+
+	start = time.UnixMicro(startUs)
+	end = time.UnixMicro(endUs)
+	return start, end, end.Sub(start)
+}
+
+// orphanMetadataKey marks session tree roots whose parent span was not part
+// of the session's results; its value is the missing parent's edge ID.
+const orphanMetadataKey = "orphaned_parent_span_id"
+
+// GetSessionTree fetches every span in a session and assembles them into a
+// forest using ParentSpanID, for sessions with more than one root span.
+// Children are sorted by start time, as are the roots.
+//
+// Spans whose parent is not in the session are returned as extra roots
+// after the real ones, with the missing parent's ID recorded in their
+// metadata under "orphaned_parent_span_id". Spans caught in a parent cycle
+// are returned as roots the same way.
+func (c *Client) GetSessionTree(ctx context.Context, sessionID int64) ([]TraceTreeNode, error) {
+	var traces []TraceView
+	for trace, err := range c.IterateTraces(ctx, &QueryFilter{SessionID: &sessionID}) {
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, trace)
+	}
+	return buildSessionForest(traces), nil
+}
+
+// buildSessionForest links traces into trees by ParentSpanID.
+func buildSessionForest(traces []TraceView) []TraceTreeNode {
+	sort.SliceStable(traces, func(i, j int) bool {
+		return traces[i].TimestampUs < traces[j].TimestampUs
+	})
+
+	byID := make(map[string]TraceView, len(traces))
+	for _, trace := range traces {
+		byID[trace.EdgeID] = trace
+	}
+	children := make(map[string][]TraceView)
+	var roots, orphans []TraceView
+	for _, trace := range traces {
+		switch _, ok := byID[trace.ParentSpanID]; {
+		case trace.ParentSpanID == "" || trace.ParentSpanID == trace.EdgeID:
+			roots = append(roots, trace)
+		case !ok:
+			orphans = append(orphans, trace)
+		default:
+			children[trace.ParentSpanID] = append(children[trace.ParentSpanID], trace)
+		}
+	}
+
+	visited := make(map[string]bool, len(traces))
+	var build func(trace TraceView) TraceTreeNode
+	build = func(trace TraceView) TraceTreeNode {
+		visited[trace.EdgeID] = true
+		node := TraceTreeNode{
+			EdgeID:     trace.EdgeID,
+			SpanType:   trace.SpanType,
+			DurationUs: trace.DurationUs,
+			Metadata:   trace.Metadata,
+		}
+		for _, child := range children[trace.EdgeID] {
+			if !visited[child.EdgeID] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
+	}
+	orphanRoot := func(trace TraceView) TraceTreeNode {
+		node := build(trace)
+		metadata := make(map[string]interface{}, len(node.Metadata)+1)
+		for k, v := range node.Metadata {
+			metadata[k] = v
+		}
+		metadata[orphanMetadataKey] = trace.ParentSpanID
+		node.Metadata = metadata
+		return node
+	}
+
+	forest := make([]TraceTreeNode, 0, len(roots)+len(orphans))
+	for _, trace := range roots {
+		forest = append(forest, build(trace))
+	}
+	for _, trace := range orphans {
+		forest = append(forest, orphanRoot(trace))
+	}
+	// Whatever is still unvisited hangs off a parent cycle.
+	for _, trace := range traces {
+		if !visited[trace.EdgeID] {
+			forest = append(forest, orphanRoot(trace))
+		}
+	}
+	return forest
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// shutdownFlushTimeout bounds how long InstallShutdownFlush lets the client
+// flush once a shutdown signal arrives.
+const shutdownFlushTimeout = 5 * time.Second
+
+// InstallShutdownFlush closes client when one of signals is received so
+// pending work is flushed before the process exits. SIGINT and SIGTERM are
+// used when no signals are given. The returned func uninstalls the handler.
+//
+// Like any signal.Notify handler, it does not terminate the process; the
+// application's own shutdown handling remains responsible for exiting.
+//
+// Example:
+//
+//	stop := agentreplay.InstallShutdownFlush(client, syscall.SIGTERM)
+//	defer stop()
+func InstallShutdownFlush(client *Client, signals ...os.Signal) func() {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-sigCh:
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+			defer cancel()
+			if err := client.CloseWithContext(ctx); err != nil {
+				client.logger.Warn("failed to flush on shutdown", errorAttr(err))
+			}
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// sdkModulePath is the import path of this module's root package. Frames in
+// it or any of its subpackages are SDK frames when locating the caller.
+var sdkModulePath = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	return name[:slash+1+dot]
+}()
+
+// sdkCallerPrefixes are the packages that call into the SDK on the
+// application's behalf: net/http invokes WrapTransport's RoundTrip, and the
+// OTel SDK invokes agentreplayotel's exporter. Their frames are skipped when
+// they sit between SDK frames and the application.
+var sdkCallerPrefixes = []string{"net/http.", "go.opentelemetry.io/otel/"}
+
+// WithSourceLocation stamps every created span with the location of the
+// application code that created it, as the OTel code.filepath, code.lineno
+// and code.function attributes. Frames of this module, and of net/http or
+// the OTel SDK calling into it, are skipped, so the location is the caller of
+// CreateTrace, Span.End, an http.Client using WrapTransport and the like.
+// Spans with no application frame on the stack get no location. It is off
+// by default because walking the stack on every span has a measurable cost.
+func WithSourceLocation(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.sourceLocation = enabled
+	}
+}
+
+// WithAutoName names spans created by CreateTrace and StartSpan after the
+// application function that created them, such as "agent.(*Planner).Plan",
+// instead of the generic "span_<agentID>". A "name" entry in Metadata still
+// takes precedence. StartSpan takes the name when the span starts, so
+// ending it elsewhere does not change it. Like WithSourceLocation, this
+// walks the stack once per span.
+func WithAutoName() ClientOption {
+	return func(c *Client) {
+		c.autoName = true
+	}
+}
+
+// applySourceLocation records the first application caller, if there is
+// one.
+func applySourceLocation(attributes map[string]string) {
+	if frame, ok := callerFrame(); ok {
+		attributes["code.filepath"] = frame.File
+		attributes["code.lineno"] = strconv.Itoa(frame.Line)
+		attributes["code.function"] = frame.Function
+	}
+}
+
+// callerName returns the name of the first application function on the
+// stack, without its import path, or "" if there is none.
+func callerName() string {
+	frame, ok := callerFrame()
+	if !ok {
+		return ""
+	}
+	name := frame.Function
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	return name
+}
+
+// callerFrame returns the first application frame on the stack: the first
+// frame outside this module that is not net/http or the OTel SDK calling
+// into it. It reports false when the stack holds no such frame, as for
+// spans created on a goroutine started by the SDK or the OTel SDK.
+func callerFrame() (runtime.Frame, bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isSDKFrame(frame.Function) && !isSDKCallerFrame(frame.Function) {
+			if strings.HasPrefix(frame.Function, "runtime.") {
+				return runtime.Frame{}, false
+			}
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// isSDKFrame reports whether function belongs to this module.
+func isSDKFrame(function string) bool {
+	return strings.HasPrefix(function, sdkModulePath+".") || strings.HasPrefix(function, sdkModulePath+"/")
+}
+
+// isSDKCallerFrame reports whether function belongs to a package that calls
+// into the SDK on the application's behalf.
+func isSDKCallerFrame(function string) bool {
+	for _, prefix := range sdkCallerPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The source location tests live outside package agentreplay, since frames
+// of the package itself are skipped as SDK frames.
+package agentreplay_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	agentreplay "github.com/sushanthpy/agentreplay/sdks/golang"
+	"github.com/sushanthpy/agentreplay/sdks/golang/agentreplaytest"
+)
+
+func TestSourceLocationSkipsTransportFrames(t *testing.T) {
+	mock := agentreplaytest.NewMockServer()
+	defer mock.Close()
+	target := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer target.Close()
+
+	c := agentreplay.NewClient(mock.URL(), 1, agentreplay.WithSourceLocation(true))
+	defer c.Close()
+	httpClient := &http.Client{Transport: c.WrapTransport(nil)}
+
+	resp, err := httpClient.Get(target.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := c.CreateTrace(context.Background(), agentreplay.CreateTraceOptions{}); err != nil {
+		t.Fatalf("CreateTrace: %v", err)
+	}
+
+	spans := mock.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	for _, span := range spans {
+		function := span.Attributes["code.function"]
+		if !strings.HasSuffix(function, ".TestSourceLocationSkipsTransportFrames") {
+			t.Errorf("span %q has code.function %q, want the test function", span.Name, function)
+		}
+		if !strings.HasSuffix(span.Attributes["code.filepath"], "source_test.go") {
+			t.Errorf("span %q has code.filepath %q, want source_test.go", span.Name, span.Attributes["code.filepath"])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Span is a handle to an in-progress span started with StartSpan. Nothing is
+// sent until End, which records the span with its real wall-clock duration.
+type Span struct {
+	// EdgeID is the span's ID, usable as ParentID for child spans.
+	EdgeID string
+	// SessionID is the session the span belongs to.
+	SessionID int64
+
+	client *Client
+	ctx    context.Context
+	opts   CreateTraceOptions
+
+	mu     sync.Mutex
+	extra  map[string]string
+	ended  bool
+	once   sync.Once
+	result *TraceResult
+	err    error
+}
+
+// StartSpan starts a span described by opts, recording the current time as
+// its start unless opts.StartTime is set. Like the Create* methods, it falls
+// back to the parent and session on ctx, allocating a session ID when there
+// is none, so children can join it via Span.SessionID or Span.Context.
+//
+// Example:
+//
+//	span := client.StartSpan(ctx, agentreplay.CreateTraceOptions{SpanType: agentreplay.SpanTypePlanning})
+//	defer span.End()
+func (c *Client) StartSpan(ctx context.Context, opts CreateTraceOptions) *Span {
+	if opts.StartTime.IsZero() {
+		opts.StartTime = time.Now()
+	}
+	opts.SessionID, opts.ParentID = c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	if _, named := opts.Metadata["name"].(string); c.autoName && !named {
+		if caller := callerName(); caller != "" {
+			opts.Metadata = maps.Clone(opts.Metadata)
+			if opts.Metadata == nil {
+				opts.Metadata = make(map[string]interface{}, 1)
+			}
+			opts.Metadata["name"] = caller
+		}
+	}
+	return &Span{
+		EdgeID:    generateEdgeID(),
+		SessionID: opts.SessionID,
+		client:    c,
+		ctx:       ctx,
+		opts:      opts,
+	}
+}
+
+// End sends the span with the current time as its end time, giving it a
+// duration_us measured from StartSpan. Only the first call sends anything;
+// later calls return the first call's result.
+func (s *Span) End() (*TraceResult, error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.ended = true
+		opts, extra := s.opts, s.extra
+		s.mu.Unlock()
+
+		opts.EndTime = time.Now()
+		s.result, s.err = s.client.createTrace(s.ctx, s.EdgeID, opts, extra)
+	})
+	return s.result, s.err
+}
+
+// Context returns the context the span was started with, carrying the span as
+// the parent for spans created from it.
+func (s *Span) Context() context.Context {
+	return ContextWithSpan(s.ctx, s.EdgeID, s.SessionID)
+}
+
+// spanContextKey is the context key for the span set by ContextWithSpan.
+type spanContextKey struct{}
+
+// spanContext is the span identity stored on a context.
+type spanContext struct {
+	edgeID    string
+	sessionID int64
+}
+
+// ContextWithSpan returns a copy of ctx carrying edgeID and sessionID. Spans
+// created with the returned context, or one derived from it, default to
+// edgeID as their parent and join sessionID when their options leave
+// ParentID and SessionID empty.
+func ContextWithSpan(ctx context.Context, edgeID string, sessionID int64) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, spanContext{edgeID: edgeID, sessionID: sessionID})
+}
+
+// SpanFromContext returns the span stored on ctx by ContextWithSpan.
+func SpanFromContext(ctx context.Context) (edgeID string, sessionID int64, ok bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	return sc.edgeID, sc.sessionID, ok
+}
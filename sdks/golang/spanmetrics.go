@@ -0,0 +1,59 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "strconv"
+
+// metricTypeDouble marks a span metric as a numeric, aggregatable value.
+const metricTypeDouble = "double"
+
+// applyMetrics writes custom numeric measurements as metric.<name>
+// attributes, each with a metric_type.<name> marker telling the server the
+// value can be aggregated numerically.
+func applyMetrics(attributes map[string]string, metrics map[string]float64) {
+	for name, value := range metrics {
+		attributes["metric."+name] = strconv.FormatFloat(value, 'f', -1, 64)
+		attributes["metric_type."+name] = metricTypeDouble
+	}
+}
+
+// MetricValue returns the custom metric recorded on a trace under name.
+func (t TraceView) MetricValue(name string) (float64, bool) {
+	return t.metadataFloat("metric." + name)
+}
+
+// SumMetric sums the named custom metric across traces, returning the sum
+// and how many traces carried the metric.
+func SumMetric(traces []TraceView, name string) (float64, int) {
+	var sum float64
+	var count int
+	for _, trace := range traces {
+		if v, ok := trace.MetricValue(name); ok {
+			sum += v
+			count++
+		}
+	}
+	return sum, count
+}
+
+// AverageMetric averages the named custom metric across the traces that
+// carry it. It reports false when none do.
+func AverageMetric(traces []TraceView, name string) (float64, bool) {
+	sum, count := SumMetric(traces, name)
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"fmt"
+	"sync"
+)
+
+// customSpanTypes holds the names registered with RegisterCustomSpanType.
+var customSpanTypes = struct {
+	sync.RWMutex
+	names map[SpanType]string
+}{names: make(map[SpanType]string)}
+
+// RegisterCustomSpanType names a custom span type so that String, JSON
+// encoding and span type parsing resolve it. Custom values start at 16;
+// the built-in range 0-15 and SpanTypeCustom (255) cannot be registered.
+// Registering the same value and name twice is a no-op, but a value or name
+// cannot be re-bound to something else. It is safe for concurrent use and
+// is typically called from an init function.
+//
+// Example:
+//
+//	const SpanTypeCheckout agentreplay.SpanType = 100
+//
+//	func init() {
+//	    if err := agentreplay.RegisterCustomSpanType(SpanTypeCheckout, "Checkout"); err != nil {
+//	        panic(err)
+//	    }
+//	}
+func RegisterCustomSpanType(value SpanType, name string) error {
+	if value <= SpanTypeGeneration || value == SpanTypeCustom {
+		return fmt.Errorf("span type %d is reserved for built-in types", value)
+	}
+	if name == "" || name == "Unknown" {
+		return fmt.Errorf("invalid span type name %q", name)
+	}
+	if t, ok := builtinSpanType(name); ok {
+		return fmt.Errorf("span type name %q is already used by built-in type %d", name, t)
+	}
+
+	customSpanTypes.Lock()
+	defer customSpanTypes.Unlock()
+	if existing, ok := customSpanTypes.names[value]; ok && existing != name {
+		return fmt.Errorf("span type %d is already registered as %q", value, existing)
+	}
+	for t, n := range customSpanTypes.names {
+		if n == name && t != value {
+			return fmt.Errorf("span type name %q is already registered for %d", name, t)
+		}
+	}
+	customSpanTypes.names[value] = name
+	return nil
+}
+
+// Known reports whether s is a built-in span type or one registered with
+// RegisterCustomSpanType, i.e. whether String names it.
+func (s SpanType) Known() bool {
+	return s.String() != "Unknown"
+}
+
+// customSpanTypeName returns the registered name of a custom span type.
+func customSpanTypeName(s SpanType) (string, bool) {
+	customSpanTypes.RLock()
+	defer customSpanTypes.RUnlock()
+	name, ok := customSpanTypes.names[s]
+	return name, ok
+}
+
+// customSpanTypeByName returns the custom span type registered under name.
+func customSpanTypeByName(name string) (SpanType, bool) {
+	customSpanTypes.RLock()
+	defer customSpanTypes.RUnlock()
+	for t, n := range customSpanTypes.names {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"strconv"
+)
+
+// TraceSpec declaratively describes a span and its children for IngestSpec.
+type TraceSpec struct {
+	Name       string
+	SpanType   SpanType
+	Attributes map[string]string
+	Children   []TraceSpec
+}
+
+// IngestSpec builds the span tree described by spec and ingests it in a
+// single IngestBatch call. Edge IDs are generated and parent links wired
+// from the nesting. A zero sessionID uses the next auto-generated ID.
+//
+// Example:
+//
+//	resp, err := client.IngestSpec(ctx, 123, agentreplay.TraceSpec{
+//	    Name:     "agent",
+//	    SpanType: agentreplay.SpanTypeRoot,
+//	    Children: []agentreplay.TraceSpec{
+//	        {Name: "plan", SpanType: agentreplay.SpanTypePlanning},
+//	        {Name: "search", SpanType: agentreplay.SpanTypeToolCall},
+//	    },
+//	})
+func (c *Client) IngestSpec(ctx context.Context, sessionID int64, spec TraceSpec) (*IngestResponse, error) {
+	if sessionID == 0 {
+		sessionID = c.nextSessionID()
+	}
+	traceID := strconv.FormatInt(sessionID, 10)
+	nowUs := nowMicroseconds()
+	used := make(map[string]bool)
+
+	var spans []SpanInput
+	var build func(spec TraceSpec, parentID *string)
+	build = func(spec TraceSpec, parentID *string) {
+		edgeID := generateEdgeID()
+		for used[edgeID] {
+			edgeID = generateEdgeID()
+		}
+		used[edgeID] = true
+
+		attributes := make(map[string]string, len(spec.Attributes)+5)
+		for k, v := range spec.Attributes {
+			attributes[k] = v
+		}
+		attributes["tenant_id"] = strconv.FormatInt(c.tenantID, 10)
+		attributes["project_id"] = strconv.FormatInt(c.projectID, 10)
+		attributes["agent_id"] = strconv.FormatInt(c.agentID, 10)
+		attributes["session_id"] = traceID
+		attributes["span_type"] = strconv.Itoa(int(spec.SpanType))
+
+		name := spec.Name
+		if name == "" {
+			name = spec.SpanType.String()
+		}
+
+		spans = append(spans, SpanInput{
+			SpanID:       edgeID,
+			TraceID:      traceID,
+			ParentSpanID: parentID,
+			Name:         name,
+			StartTime:    nowUs,
+			EndTime:      &nowUs,
+			Attributes:   attributes,
+		})
+		for _, child := range spec.Children {
+			build(child, &edgeID)
+		}
+	}
+	build(spec, nil)
+
+	return c.IngestBatch(ctx, spans)
+}
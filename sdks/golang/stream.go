@@ -0,0 +1,106 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GenAIStream accumulates a streamed model response and records it as a
+// GenAI trace once the stream ends. It is safe for concurrent use.
+type GenAIStream struct {
+	// EdgeID is the span's ID, usable as ParentID for child spans.
+	EdgeID string
+	// SessionID is the session the span belongs to.
+	SessionID int64
+
+	client *Client
+	ctx    context.Context
+	opts   CreateGenAITraceOptions
+
+	mu         sync.Mutex
+	output     strings.Builder
+	firstChunk time.Time
+
+	once   sync.Once
+	result *GenAITraceResult
+	err    error
+}
+
+// StartGenAIStream starts timing a streamed model call described by opts.
+// Output, FinishReason and the usage fields are filled in by EndStream, so
+// they can be left unset.
+func (c *Client) StartGenAIStream(ctx context.Context, opts CreateGenAITraceOptions) *GenAIStream {
+	if opts.StartTime.IsZero() {
+		opts.StartTime = time.Now()
+	}
+	opts.SessionID, opts.ParentID = c.spanLineage(ctx, opts.SessionID, opts.ParentID)
+	return &GenAIStream{
+		EdgeID:    generateEdgeID(),
+		SessionID: opts.SessionID,
+		client:    c,
+		ctx:       ctx,
+		opts:      opts,
+	}
+}
+
+// AppendChunk appends a streamed delta to the output. The time of the first
+// call is recorded as the time to first token.
+func (s *GenAIStream) AppendChunk(delta string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.firstChunk.IsZero() {
+		s.firstChunk = time.Now()
+	}
+	s.output.WriteString(delta)
+}
+
+// EndStream sends the GenAI trace with the assembled output as an assistant
+// message, along with finishReason and usage. Zero usage counts are omitted,
+// and a zero TotalTokens defaults to the sum of the other two.
+// The time to first token, in seconds, is recorded as
+// gen_ai.server.time_to_first_token when any chunk was appended. Only the
+// first call sends anything; later calls return the first call's result.
+func (s *GenAIStream) EndStream(finishReason string, usage Usage) (*GenAITraceResult, error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		opts := s.opts
+		opts.EndTime = time.Now()
+		opts.Output = &Message{Role: "assistant", Content: s.output.String()}
+		firstChunk := s.firstChunk
+		s.mu.Unlock()
+
+		if finishReason != "" {
+			opts.FinishReason = finishReason
+		}
+		if usage != (Usage{}) {
+			opts.Usage = &usage
+		}
+
+		var extra map[string]string
+		if !firstChunk.IsZero() {
+			ttft := firstChunk.Sub(opts.StartTime).Seconds()
+			extra = map[string]string{
+				"gen_ai.server.time_to_first_token": strconv.FormatFloat(ttft, 'f', -1, 64),
+			}
+		}
+		s.result, s.err = s.client.createGenAITrace(s.ctx, s.EdgeID, opts, extra)
+	})
+	return s.result, s.err
+}
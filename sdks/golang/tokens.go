@@ -0,0 +1,117 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "unicode/utf8"
+
+// Tokenizer estimates how many tokens a piece of text takes up.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) int
+
+// CountTokens implements Tokenizer.
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// HeuristicTokenizer returns a Tokenizer that assumes four characters per
+// token, which is roughly right for English text with BPE tokenizers. It
+// is the default.
+func HeuristicTokenizer() Tokenizer {
+	return TokenizerFunc(func(text string) int {
+		return (utf8.RuneCountInString(text) + 3) / 4
+	})
+}
+
+// WithTokenizer sets the tokenizer CreateGenAITrace uses to estimate token
+// usage counts missing from Usage, for models that do not report
+// it. Estimated counts feed the usage and cost attributes like reported
+// ones, and the span is marked with gen_ai.usage.estimated=true. Plug in a
+// real BPE tokenizer for accurate counts, or pass nil to turn estimation
+// off.
+func WithTokenizer(tokenizer Tokenizer) ClientOption {
+	return func(c *Client) {
+		c.tokenizer = tokenizer
+	}
+}
+
+// estimateUsage returns the token usage for opts, estimating the input and
+// output counts that were not provided. A missing or derived total is
+// recomputed from the input and output counts when either was estimated.
+func (c *Client) estimateUsage(opts CreateGenAITraceOptions) (input, output, total *int, estimated bool) {
+	input, output, total = opts.reportedUsage()
+	if c.tokenizer == nil {
+		return input, output, total, false
+	}
+
+	if input == nil && len(opts.InputMessages) > 0 {
+		n := 0
+		for _, message := range opts.InputMessages {
+			n += c.messageTokens(message)
+		}
+		input = &n
+		estimated = true
+	}
+	if output == nil && opts.Output != nil {
+		n := c.messageTokens(*opts.Output)
+		output = &n
+		estimated = true
+	}
+	derivedTotal := total == nil || (opts.Usage != nil && opts.Usage.TotalTokens == 0)
+	if derivedTotal && estimated && input != nil && output != nil {
+		n := *input + *output
+		total = &n
+	}
+	return input, output, total, estimated
+}
+
+// reportedUsage returns the usage given in opts, preferring Usage over the
+// deprecated pointer fields. From Usage, zero counts are left nil and a
+// missing total is the sum of input and output.
+func (opts CreateGenAITraceOptions) reportedUsage() (input, output, total *int) {
+	u := opts.Usage
+	if u == nil {
+		return opts.InputUsage, opts.OutputUsage, opts.TotalUsage
+	}
+	total = positive(u.TotalTokens)
+	if total == nil {
+		total = positive(u.InputTokens + u.OutputTokens)
+	}
+	return positive(u.InputTokens), positive(u.OutputTokens), total
+}
+
+// positive returns a pointer to n, or nil if n is not positive.
+func positive(n int) *int {
+	if n <= 0 {
+		return nil
+	}
+	return &n
+}
+
+// messageTokens counts the tokens in a message's text content and tool
+// calls.
+func (c *Client) messageTokens(message Message) int {
+	n := c.tokenizer.CountTokens(message.Content)
+	for _, part := range message.Parts {
+		n += c.tokenizer.CountTokens(part.Text)
+	}
+	for _, call := range message.ToolCalls {
+		n += c.tokenizer.CountTokens(call.Name) + c.tokenizer.CountTokens(call.Arguments)
+	}
+	return n
+}
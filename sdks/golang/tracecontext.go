@@ -0,0 +1,130 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// traceparentHeader is the W3C Trace Context header name.
+const traceparentHeader = "traceparent"
+
+// TraceParent is a parsed W3C traceparent header.
+type TraceParent struct {
+	// TraceID is the 32-character hex trace ID.
+	TraceID string
+	// ParentID is the caller's span, as an edge ID.
+	ParentID string
+	// Sampled reports whether the caller recorded the trace.
+	Sampled bool
+}
+
+// session maps the trace to a session. Agentreplay callers put the session
+// ID in the low 8 bytes of the trace ID and zero the rest, so native reports
+// whether the ID was decoded as-is. Other trace IDs are folded into a
+// positive session ID, so every span of a foreign trace shares a session.
+func (tp TraceParent) session() (id int64, native bool) {
+	b, err := hex.DecodeString(tp.TraceID)
+	if err != nil || len(b) != 16 {
+		return 0, false
+	}
+	low := binary.BigEndian.Uint64(b[8:])
+	if binary.BigEndian.Uint64(b[:8]) == 0 && low <= math.MaxInt64 {
+		return int64(low), true
+	}
+	id = int64(low & math.MaxInt64)
+	if id == 0 {
+		id = 1
+	}
+	return id, false
+}
+
+// InjectTraceContext sets a traceparent header for the span on ctx, so a
+// downstream service can join the trace. The session ID becomes the trace ID
+// and the edge ID the parent span ID, using the same mapping as OTLP export.
+// It does nothing when ctx carries no span.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	edgeID, sessionID, ok := SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+	traceID := hex.EncodeToString(otlpTraceID(strconv.FormatInt(sessionID, 10)))
+	if tp, ok := ctx.Value(traceParentKey{}).(TraceParent); ok {
+		if _, native := tp.session(); !native {
+			traceID = tp.TraceID
+		}
+	}
+	header.Set(traceparentHeader, fmt.Sprintf("00-%s-%s-01", traceID, hex.EncodeToString(otlpSpanID(edgeID))))
+}
+
+// ExtractTraceContext parses the traceparent header of an incoming request.
+// It returns false when the header is missing or malformed. Pass the result
+// to ContextWithTraceParent to continue the caller's trace.
+func ExtractTraceContext(header http.Header) (TraceParent, bool) {
+	parts := strings.Split(strings.TrimSpace(header.Get(traceparentHeader)), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return TraceParent{}, false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || strings.Trim(parts[1], "0") == "" {
+		return TraceParent{}, false
+	}
+	spanID, err := strconv.ParseUint(parts[2], 16, 64)
+	if err != nil || spanID == 0 {
+		return TraceParent{}, false
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return TraceParent{}, false
+	}
+	return TraceParent{
+		TraceID:  strings.ToLower(parts[1]),
+		ParentID: strconv.FormatUint(spanID, 16),
+		Sampled:  flags&1 == 1,
+	}, true
+}
+
+// traceParentKey is the context key for an incoming TraceParent.
+type traceParentKey struct{}
+
+// ContextWithTraceParent returns a copy of ctx carrying an incoming trace.
+// Spans created with it default to tp.ParentID as their parent. When the
+// caller is another Agentreplay client they join its session. Otherwise they
+// share a session derived from the trace ID and adopt tp.TraceID as their
+// TraceID, so they line up with the caller's OpenTelemetry trace.
+func ContextWithTraceParent(ctx context.Context, tp TraceParent) context.Context {
+	ctx = context.WithValue(ctx, traceParentKey{}, tp)
+	sessionID, _ := tp.session()
+	return ContextWithSpan(ctx, tp.ParentID, sessionID)
+}
+
+// adoptTraceID replaces the span's trace ID with a foreign one carried on
+// ctx by ContextWithTraceParent.
+func adoptTraceID(ctx context.Context, span *SpanInput) {
+	tp, ok := ctx.Value(traceParentKey{}).(TraceParent)
+	if !ok {
+		return
+	}
+	if _, native := tp.session(); !native {
+		span.TraceID = tp.TraceID
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import "strconv"
+
+// TokenUsage returns the token usage recorded on a GenAI trace. Input and
+// output fall back to the legacy prompt and completion keys, and a missing
+// total is derived from them. It reports false when no usage was recorded.
+func (t TraceView) TokenUsage() (input, output, total int, ok bool) {
+	input, hasInput := t.metadataInt("gen_ai.usage.input_tokens", "gen_ai.usage.prompt_tokens")
+	output, hasOutput := t.metadataInt("gen_ai.usage.output_tokens", "gen_ai.usage.completion_tokens")
+	total, hasTotal := t.metadataInt("gen_ai.usage.total_tokens")
+	if !hasTotal && (hasInput || hasOutput) {
+		total = input + output
+	}
+	return input, output, total, hasInput || hasOutput || hasTotal
+}
+
+// Model returns the model a GenAI trace ran against, preferring the model
+// reported in the response over the one requested. It is empty for other
+// traces.
+func (t TraceView) Model() string {
+	return t.metadataString("gen_ai.response.model", "gen_ai.request.model")
+}
+
+// ToolName returns the tool a tool trace called, or "" for other traces.
+func (t TraceView) ToolName() string {
+	return t.metadataString("gen_ai.tool.name")
+}
+
+// metadataString returns the first of keys present in the metadata as a
+// string.
+func (t TraceView) metadataString(keys ...string) string {
+	for _, key := range keys {
+		if v, ok := t.Metadata[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// metadataInt returns the first of keys present in the metadata as an int.
+// Values may be JSON numbers or numeric strings, since attributes are sent
+// as strings.
+func (t TraceView) metadataInt(keys ...string) (int, bool) {
+	for _, key := range keys {
+		if v, ok := t.metadataFloat(key); ok {
+			return int(v), true
+		}
+	}
+	return 0, false
+}
+
+// metadataFloat returns the metadata value under key as a float64.
+func (t TraceView) metadataFloat(key string) (float64, bool) {
+	switch v := t.Metadata[key].(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
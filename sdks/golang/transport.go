@@ -0,0 +1,123 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WrapTransport returns an http.RoundTripper that records every request made
+// through base as a SpanTypeHttpCall span, with its method, URL, status code
+// and duration. The parent and session come from the request's context (see
+// ContextWithSpan). Failed requests and non-2xx responses are recorded as
+// SpanTypeError. A nil base uses http.DefaultTransport.
+//
+// Requests to the Agentreplay server itself are passed through untraced, so
+// the wrapped transport can also back the client's own HTTP client. Spans
+// are sent as by CreateTrace, before RoundTrip returns; use WithAsyncBuffer
+// to keep them off the request path. Failures to record a span never affect
+// the request.
+//
+// Example:
+//
+//	httpClient := &http.Client{Transport: client.WrapTransport(nil)}
+func (c *Client) WrapTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	server, _ := url.Parse(c.url)
+	return &tracingTransport{client: c, base: base, server: server}
+}
+
+// tracingTransport is the RoundTripper returned by WrapTransport.
+type tracingTransport struct {
+	client *Client
+	base   http.RoundTripper
+	server *url.URL // the client's base URL, nil if it does not parse
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isServerURL(t.server, req.URL) {
+		return t.base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	end := time.Now()
+
+	attributes := map[string]string{
+		"http.request.method": req.Method,
+		"url.full":            req.URL.Redacted(),
+		"server.address":      req.URL.Hostname(),
+	}
+	spanType := SpanTypeHttpCall
+	switch {
+	case err != nil:
+		spanType = SpanTypeError
+		attributes["error.type"] = fmt.Sprintf("%T", err)
+		attributes["exception.message"] = err.Error()
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		spanType = SpanTypeError
+		attributes["http.response.status_code"] = strconv.Itoa(resp.StatusCode)
+		attributes["error.type"] = strconv.Itoa(resp.StatusCode)
+	default:
+		attributes["http.response.status_code"] = strconv.Itoa(resp.StatusCode)
+	}
+
+	t.client.createTrace(req.Context(), generateEdgeID(), CreateTraceOptions{
+		SpanType:  spanType,
+		StartTime: start,
+		EndTime:   end,
+		Metadata:  map[string]interface{}{"name": req.Method + " " + req.URL.Host},
+	}, attributes)
+
+	return resp, err
+}
+
+// isServerURL reports whether u addresses the Agentreplay server at base:
+// the same scheme, host and port, and a path equal to or under base's path.
+func isServerURL(base, u *url.URL) bool {
+	if base == nil {
+		return false
+	}
+	if !strings.EqualFold(u.Scheme, base.Scheme) ||
+		!strings.EqualFold(u.Hostname(), base.Hostname()) ||
+		urlPort(u) != urlPort(base) {
+		return false
+	}
+	basePath := strings.TrimSuffix(base.Path, "/")
+	return u.Path == basePath || strings.HasPrefix(u.Path, basePath+"/")
+}
+
+// urlPort returns u's port, or the default port of its scheme when none is
+// given.
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
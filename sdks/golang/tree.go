@@ -0,0 +1,158 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TreeExpectation is an additional check run by ValidateTree.
+type TreeExpectation func(root TraceTreeNode) error
+
+// ValidateTree checks that a trace tree is well-formed: exactly one root,
+// no cycles and no duplicate edge IDs. The given expectations are run as
+// well. An empty result means the tree passed every check.
+//
+// Example:
+//
+//	errs := agentreplay.ValidateTree(tree.Root,
+//	    agentreplay.MustContainSpanType(agentreplay.SpanTypeToolCall),
+//	    agentreplay.MustHaveChildSpanType(agentreplay.SpanTypeToolCall, agentreplay.SpanTypeToolResponse),
+//	)
+func ValidateTree(root TraceTreeNode, expectations ...TreeExpectation) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	ancestors := make(map[string]bool)
+
+	var walk func(node TraceTreeNode, depth int)
+	walk = func(node TraceTreeNode, depth int) {
+		if node.EdgeID == "" {
+			errs = append(errs, fmt.Errorf("span at depth %d has an empty edge ID", depth))
+		} else {
+			if ancestors[node.EdgeID] {
+				errs = append(errs, fmt.Errorf("cycle detected: span %s is its own ancestor", node.EdgeID))
+				return
+			}
+			if seen[node.EdgeID] {
+				errs = append(errs, fmt.Errorf("duplicate edge ID %s", node.EdgeID))
+			}
+			seen[node.EdgeID] = true
+		}
+		if depth > 0 && spanTypeIs(node.SpanType, SpanTypeRoot) {
+			errs = append(errs, fmt.Errorf("span %s is a root span but is nested at depth %d", node.EdgeID, depth))
+		}
+
+		ancestors[node.EdgeID] = true
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+		delete(ancestors, node.EdgeID)
+	}
+	walk(root, 0)
+
+	for _, expect := range expectations {
+		if err := expect(root); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// MustContainSpanType expects at least one span of the given type in the tree.
+func MustContainSpanType(spanType SpanType) TreeExpectation {
+	return func(root TraceTreeNode) error {
+		found := false
+		walkTree(root, func(node TraceTreeNode) {
+			if spanTypeIs(node.SpanType, spanType) {
+				found = true
+			}
+		})
+		if !found {
+			return fmt.Errorf("expected a %s span in the tree", spanType)
+		}
+		return nil
+	}
+}
+
+// MustHaveChildSpanType expects every span of type parent to have at least
+// one direct child of type child, e.g. every tool call to have a response.
+func MustHaveChildSpanType(parent, child SpanType) TreeExpectation {
+	return func(root TraceTreeNode) error {
+		var missing []string
+		walkTree(root, func(node TraceTreeNode) {
+			if !spanTypeIs(node.SpanType, parent) {
+				return
+			}
+			for _, c := range node.Children {
+				if spanTypeIs(c.SpanType, child) {
+					return
+				}
+			}
+			missing = append(missing, node.EdgeID)
+		})
+		if len(missing) > 0 {
+			return fmt.Errorf("%s spans without a %s child: %v", parent, child, missing)
+		}
+		return nil
+	}
+}
+
+// walkTree calls fn for every node in the tree, depth first.
+func walkTree(node TraceTreeNode, fn func(TraceTreeNode)) {
+	fn(node)
+	for _, child := range node.Children {
+		walkTree(child, fn)
+	}
+}
+
+// pruneTree applies opts to nodes sitting at the given depth, returning the
+// nodes to keep in their place. Excluded nodes are replaced by their kept
+// descendants.
+func pruneTree(nodes []TraceTreeNode, opts TreeOptions, depth int) []TraceTreeNode {
+	if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+		return nil
+	}
+	var kept []TraceTreeNode
+	for _, node := range nodes {
+		children := pruneTree(node.Children, opts, depth+1)
+		if !treeIncludes(node, opts) {
+			kept = append(kept, children...)
+			continue
+		}
+		node.Children = children
+		kept = append(kept, node)
+	}
+	return kept
+}
+
+// treeIncludes reports whether node passes the span type filter in opts.
+func treeIncludes(node TraceTreeNode, opts TreeOptions) bool {
+	if len(opts.IncludeSpanTypes) == 0 {
+		return true
+	}
+	for _, t := range opts.IncludeSpanTypes {
+		if spanTypeIs(node.SpanType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// spanTypeIs reports whether a span type as returned by the API, either the
+// name or the numeric value, matches t.
+func spanTypeIs(value string, t SpanType) bool {
+	return value == t.String() || value == strconv.Itoa(int(t))
+}
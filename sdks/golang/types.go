@@ -17,7 +17,10 @@
 package agentreplay
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -57,7 +60,8 @@ const (
 	SpanTypeParsing SpanType = 14
 	// SpanTypeGeneration represents content generation
 	SpanTypeGeneration SpanType = 15
-	// SpanTypeCustom represents custom types (use values >= 16)
+	// SpanTypeCustom represents custom types (use values >= 16, named with
+	// RegisterCustomSpanType)
 	SpanTypeCustom SpanType = 255
 )
 
@@ -85,9 +89,72 @@ func (s SpanType) String() string {
 	if name, ok := names[s]; ok {
 		return name
 	}
+	if name, ok := customSpanTypeName(s); ok {
+		return name
+	}
 	return "Unknown"
 }
 
+// parseSpanType parses a span type as returned by the API, either its name
+// (as produced by String) or its numeric value.
+func parseSpanType(value string) (SpanType, bool) {
+	if n, err := strconv.Atoi(value); err == nil {
+		return SpanType(n), true
+	}
+	if t, ok := builtinSpanType(value); ok {
+		return t, true
+	}
+	return customSpanTypeByName(value)
+}
+
+// builtinSpanType returns the built-in span type with the given name.
+func builtinSpanType(name string) (SpanType, bool) {
+	for t := SpanTypeRoot; t <= SpanTypeGeneration; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	if name == SpanTypeCustom.String() {
+		return SpanTypeCustom, true
+	}
+	return 0, false
+}
+
+// MarshalJSON encodes the span type as its name from String. Values without
+// a name are encoded as plain numbers so they survive a round trip. Earlier
+// versions always encoded numbers, which UnmarshalJSON still accepts. This
+// covers SpanType fields only: span_type attributes and query params are
+// always numeric strings.
+func (s SpanType) MarshalJSON() ([]byte, error) {
+	name := s.String()
+	if name == "Unknown" {
+		return []byte(strconv.Itoa(int(s))), nil
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON decodes a span type from a JSON number or a string holding
+// either its name or its number. Names it does not recognize decode as
+// SpanTypeCustom rather than failing, so newer server types don't break
+// decoding.
+func (s *SpanType) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*s = SpanType(n)
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("span type must be a string or number: %w", err)
+	}
+	t, ok := parseSpanType(value)
+	if !ok {
+		t = SpanTypeCustom
+	}
+	*s = t
+	return nil
+}
+
 // SensitivityFlags represents sensitivity flags for PII and redaction control.
 type SensitivityFlags uint8
 
@@ -113,6 +180,25 @@ const (
 	EnvironmentProduction  Environment = "production"
 )
 
+// Known reports whether e is one of the predefined environments.
+func (e Environment) Known() bool {
+	switch e {
+	case EnvironmentDevelopment, EnvironmentStaging, EnvironmentProduction:
+		return true
+	default:
+		return false
+	}
+}
+
+// GuardrailAction is the action a guardrail took on the checked content.
+type GuardrailAction string
+
+const (
+	GuardrailActionAllow  GuardrailAction = "allow"
+	GuardrailActionBlock  GuardrailAction = "block"
+	GuardrailActionRedact GuardrailAction = "redact"
+)
+
 // TraceResult contains the result of creating a trace.
 type TraceResult struct {
 	EdgeID    string   `json:"edge_id"`
@@ -140,45 +226,116 @@ type ToolTraceResult struct {
 	ToolName  string `json:"tool_name"`
 }
 
+// DecisionTraceResult contains the result of creating a decision trace.
+type DecisionTraceResult struct {
+	EdgeID       string `json:"edge_id"`
+	TenantID     int64  `json:"tenant_id"`
+	AgentID      int64  `json:"agent_id"`
+	SessionID    int64  `json:"session_id"`
+	DecisionName string `json:"decision_name"`
+}
+
+// ReasoningTraceResult contains the result of creating a reasoning trace.
+type ReasoningTraceResult struct {
+	EdgeID     string `json:"edge_id"`
+	TenantID   int64  `json:"tenant_id"`
+	AgentID    int64  `json:"agent_id"`
+	SessionID  int64  `json:"session_id"`
+	StepNumber int    `json:"step_number,omitempty"`
+}
+
+// EmbeddingTraceResult contains the result of creating an embedding trace.
+type EmbeddingTraceResult struct {
+	EdgeID    string `json:"edge_id"`
+	TenantID  int64  `json:"tenant_id"`
+	AgentID   int64  `json:"agent_id"`
+	SessionID int64  `json:"session_id"`
+	Model     string `json:"model,omitempty"`
+}
+
+// RetrievalTraceResult contains the result of creating a retrieval trace.
+type RetrievalTraceResult struct {
+	EdgeID        string `json:"edge_id"`
+	TenantID      int64  `json:"tenant_id"`
+	AgentID       int64  `json:"agent_id"`
+	SessionID     int64  `json:"session_id"`
+	DocumentCount int    `json:"document_count"`
+}
+
+// GuardrailTraceResult contains the result of creating a guardrail trace.
+type GuardrailTraceResult struct {
+	EdgeID        string `json:"edge_id"`
+	TenantID      int64  `json:"tenant_id"`
+	AgentID       int64  `json:"agent_id"`
+	SessionID     int64  `json:"session_id"`
+	GuardrailName string `json:"guardrail_name"`
+	Passed        bool   `json:"passed"`
+}
+
 // TraceView represents a trace as returned by the API.
 type TraceView struct {
-	EdgeID      string                 `json:"edge_id"`
-	TenantID    int64                  `json:"tenant_id"`
-	ProjectID   int64                  `json:"project_id"`
-	AgentID     int64                  `json:"agent_id"`
-	AgentName   string                 `json:"agent_name,omitempty"`
-	SessionID   int64                  `json:"session_id"`
-	SpanType    string                 `json:"span_type"`
-	TimestampUs int64                  `json:"timestamp_us"`
-	DurationUs  int64                  `json:"duration_us"`
-	TokenCount  int                    `json:"token_count"`
-	Confidence  float64                `json:"confidence"`
-	Environment string                 `json:"environment"`
-	HasPayload  bool                   `json:"has_payload"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	EdgeID       string                 `json:"edge_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Name         string                 `json:"name,omitempty"`
+	TenantID     int64                  `json:"tenant_id"`
+	ProjectID    int64                  `json:"project_id"`
+	AgentID      int64                  `json:"agent_id"`
+	AgentName    string                 `json:"agent_name,omitempty"`
+	SessionID    int64                  `json:"session_id"`
+	SpanType     string                 `json:"span_type"`
+	TimestampUs  int64                  `json:"timestamp_us"`
+	DurationUs   int64                  `json:"duration_us"`
+	TokenCount   int                    `json:"token_count"`
+	Confidence   float64                `json:"confidence"`
+	Environment  string                 `json:"environment"`
+	HasPayload   bool                   `json:"has_payload"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // QueryResponse represents the response from query operations.
+//
+// AppliedFilters echoes the filters the query ran with, as reported by the
+// server or, failing that, as sent by the client.
 type QueryResponse struct {
-	Traces []TraceView `json:"traces"`
-	Total  int         `json:"total"`
-	Limit  int         `json:"limit"`
-	Offset int         `json:"offset"`
+	Traces         []TraceView       `json:"traces"`
+	Total          int               `json:"total"`
+	Limit          int               `json:"limit"`
+	Offset         int               `json:"offset"`
+	AppliedFilters map[string]string `json:"applied_filters,omitempty"`
 }
 
 // QueryFilter contains filters for querying traces.
+//
+// MinDurationUs and MaxDurationUs bound duration_us inclusively, e.g. a
+// MinDurationUs of 500000 finds spans slower than 500ms.
+//
+// Attributes matches spans by exact attribute value, sent as one
+// attr.<key>=<value> param per entry. Constraints are ANDed, so a span is
+// returned only if every listed attribute matches.
 type QueryFilter struct {
-	TenantID       int64       `json:"tenant_id,omitempty"`
-	ProjectID      *int64      `json:"project_id,omitempty"`
-	AgentID        *int64      `json:"agent_id,omitempty"`
-	SessionID      *int64      `json:"session_id,omitempty"`
-	SpanType       *SpanType   `json:"span_type,omitempty"`
-	MinConfidence  *float64    `json:"min_confidence,omitempty"`
-	ExcludePII     bool        `json:"exclude_pii,omitempty"`
-	ExcludeSecrets bool        `json:"exclude_secrets,omitempty"`
-	Environment    Environment `json:"environment,omitempty"`
-	Limit          int         `json:"limit,omitempty"`
-	Offset         int         `json:"offset,omitempty"`
+	TenantID       int64             `json:"tenant_id,omitempty"`
+	ProjectID      *int64            `json:"project_id,omitempty"`
+	AgentID        *int64            `json:"agent_id,omitempty"`
+	SessionID      *int64            `json:"session_id,omitempty"`
+	SpanType       *SpanType         `json:"span_type,omitempty"`
+	SpanTypes      []SpanType        `json:"span_types,omitempty"`
+	Model          *string           `json:"model,omitempty"`
+	MinConfidence  *float64          `json:"min_confidence,omitempty"`
+	MaxConfidence  *float64          `json:"max_confidence,omitempty"`
+	ExcludePII     bool              `json:"exclude_pii,omitempty"`
+	ExcludeSecrets bool              `json:"exclude_secrets,omitempty"`
+	Environment    Environment       `json:"environment,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"`
+	Tags           []string          `json:"tags,omitempty"`
+	TagsMatchAll   bool              `json:"tags_match_all,omitempty"`
+	HasFeedback    *bool             `json:"has_feedback,omitempty"`
+	MinFeedback    *int              `json:"min_feedback,omitempty"`
+	MaxFeedback    *int              `json:"max_feedback,omitempty"`
+	Synthetic      *bool             `json:"synthetic,omitempty"`
+	MinDurationUs  *int64            `json:"min_duration_us,omitempty"`
+	MaxDurationUs  *int64            `json:"max_duration_us,omitempty"`
+	Limit          int               `json:"limit,omitempty"`
+	Offset         int               `json:"offset,omitempty"`
 }
 
 // SpanInput represents a span for ingestion.
@@ -190,6 +347,42 @@ type SpanInput struct {
 	StartTime    int64             `json:"start_time"`
 	EndTime      *int64            `json:"end_time,omitempty"`
 	Attributes   map[string]string `json:"attributes"`
+	Events       []SpanEvent       `json:"events,omitempty"`
+
+	// revision numbers WithRollup's re-sends of a root span so each gets
+	// its own idempotency key. It is zero for every other span.
+	revision int64
+}
+
+// SpanEvent is a timestamped event recorded within a span.
+type SpanEvent struct {
+	Name       string            `json:"name"`
+	Timestamp  int64             `json:"timestamp"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// ToolProgress is a progress report from a long-running tool call.
+type ToolProgress struct {
+	Timestamp time.Time
+	Message   string
+	Percent   float64
+}
+
+// event converts the progress report into a tool.progress span event,
+// stamped with the current time when Timestamp is zero.
+func (p ToolProgress) event() SpanEvent {
+	ts := nowMicroseconds()
+	if !p.Timestamp.IsZero() {
+		ts = p.Timestamp.UnixMicro()
+	}
+	return SpanEvent{
+		Name:      "tool.progress",
+		Timestamp: ts,
+		Attributes: map[string]string{
+			"tool.progress.message": p.Message,
+			"tool.progress.percent": strconv.FormatFloat(p.Percent, 'f', -1, 64),
+		},
+	}
 }
 
 // IngestResponse represents the response from batch ingestion.
@@ -213,67 +406,365 @@ type TraceTreeResponse struct {
 	Root TraceTreeNode `json:"root"`
 }
 
+// TreeOptions limits the trace tree returned by GetTraceTreeWithOptions.
+//
+// MaxDepth drops spans nested deeper than the given depth, where the root is
+// depth 0; zero means no limit. IncludeSpanTypes keeps only spans of the
+// listed types: excluded spans are removed and their remaining descendants
+// are attached to the nearest kept ancestor. The root is always kept.
+type TreeOptions struct {
+	MaxDepth         int        `json:"max_depth,omitempty"`
+	IncludeSpanTypes []SpanType `json:"include_span_types,omitempty"`
+}
+
 // FeedbackResponse represents the response from submitting feedback.
 type FeedbackResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
 
+// FeedbackItem is one entry of a SubmitFeedbackBatch call. Score must be
+// -1, 0, or 1; Comment is optional.
+type FeedbackItem struct {
+	TraceID string `json:"trace_id"`
+	Score   int    `json:"feedback"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// FeedbackItemResult reports the outcome of one FeedbackItem.
+type FeedbackItemResult struct {
+	TraceID string `json:"trace_id"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// FeedbackBatchResponse represents the response from SubmitFeedbackBatch,
+// with one result per item in request order.
+type FeedbackBatchResponse struct {
+	Results []FeedbackItemResult `json:"results"`
+}
+
 // DatasetResponse represents the response from adding to a dataset.
 type DatasetResponse struct {
 	Success     bool   `json:"success"`
 	DatasetName string `json:"dataset_name"`
 }
 
+// DatasetInfo summarizes an evaluation dataset.
+type DatasetInfo struct {
+	Name       string `json:"name"`
+	EntryCount int    `json:"entry_count"`
+}
+
+// DatasetEntry is a trace stored in an evaluation dataset with its input
+// and output data.
+type DatasetEntry struct {
+	TraceID string                 `json:"trace_id"`
+	Input   map[string]interface{} `json:"input,omitempty"`
+	Output  map[string]interface{} `json:"output,omitempty"`
+	AddedAt int64                  `json:"added_at,omitempty"`
+}
+
+// DatasetPage is one page of a dataset's entries.
+type DatasetPage struct {
+	DatasetName string         `json:"dataset_name"`
+	Entries     []DatasetEntry `json:"entries"`
+	Total       int            `json:"total"`
+	Limit       int            `json:"limit"`
+	Offset      int            `json:"offset"`
+}
+
 // HealthResponse represents the response from health check.
 type HealthResponse struct {
-	Status  string `json:"status"`
-	Version string `json:"version,omitempty"`
+	Status       string   `json:"status"`
+	Version      string   `json:"version,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
 }
 
 // Message represents a chat message.
+//
+// Content holds plain text. For multi-part content such as text mixed with
+// images, set Parts instead; when Parts is non-empty it is encoded as the
+// message's content in place of Content. ToolCalls records the tools an
+// assistant message asked for, and ToolCallID ties a "tool" message to the
+// call it answers.
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string
+	Content    string
+	Parts      []ContentPart
+	ToolCalls  []ToolCall
+	ToolCallID string
+}
+
+// ContentPart is one part of a multi-part message.
+type ContentPart struct {
+	// Type is the part type, e.g. "text" or "image_url".
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+// ToolCall is a tool invocation requested by the model.
+type ToolCall struct {
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type,omitempty"`
+	Name string `json:"name"`
+	// Arguments is the JSON-encoded argument object as sent by the model.
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// messageJSON is the wire form of Message. Content is either a string or a
+// list of ContentPart.
+type messageJSON struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	ToolCalls  []ToolCall      `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// MarshalJSON encodes the message as {"role", "content", ...}, with content
+// as a string or, when Parts is set, as a list of parts.
+func (m Message) MarshalJSON() ([]byte, error) {
+	var content interface{} = m.Content
+	if len(m.Parts) > 0 {
+		content = m.Parts
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(messageJSON{
+		Role:       m.Role,
+		Content:    raw,
+		ToolCalls:  m.ToolCalls,
+		ToolCallID: m.ToolCallID,
+	})
+}
+
+// UnmarshalJSON decodes either content form written by MarshalJSON.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var wire messageJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*m = Message{Role: wire.Role, ToolCalls: wire.ToolCalls, ToolCallID: wire.ToolCallID}
+
+	content := bytes.TrimSpace(wire.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+	case content[0] == '[':
+		return json.Unmarshal(content, &m.Parts)
+	default:
+		return json.Unmarshal(content, &m.Content)
+	}
+	return nil
+}
+
+// Usage is the token usage reported for a model call. Zero counts are
+// treated as not reported, and a zero TotalTokens defaults to
+// InputTokens+OutputTokens. CachedTokens are input tokens served from the
+// provider's prompt cache and ReasoningTokens are output tokens spent on
+// hidden reasoning; both are already included in InputTokens and
+// OutputTokens respectively.
+type Usage struct {
+	InputTokens     int `json:"input_tokens"`
+	OutputTokens    int `json:"output_tokens"`
+	TotalTokens     int `json:"total_tokens"`
+	CachedTokens    int `json:"cached_tokens,omitempty"`
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
 }
 
 // CreateTraceOptions contains options for creating a trace.
+//
+// TenantID and ProjectID override the client defaults for this call only.
+// StartTime and EndTime, when set, replace the current time so spans can be
+// backfilled with their original timing. Both apply to the other
+// Create*Options types as well.
 type CreateTraceOptions struct {
-	AgentID   int64
-	SessionID int64
-	SpanType  SpanType
-	ParentID  string
-	Metadata  map[string]interface{}
+	TenantID    int64
+	ProjectID   int64
+	AgentID     int64
+	SessionID   int64
+	SpanType    SpanType
+	ParentID    string
+	Metadata    map[string]interface{}
+	StartTime   time.Time
+	EndTime     time.Time
+	Tags        []string
+	Metrics     map[string]float64
+	Sensitivity SensitivityFlags
 }
 
 // CreateGenAITraceOptions contains options for creating a GenAI trace.
 type CreateGenAITraceOptions struct {
+	TenantID        int64
+	ProjectID       int64
 	AgentID         int64
 	SessionID       int64
 	InputMessages   []Message
 	Output          *Message
 	Model           string
 	ModelParameters map[string]interface{}
-	InputUsage      *int
-	OutputUsage     *int
-	TotalUsage      *int
+	Usage           *Usage
 	ParentID        string
 	Metadata        map[string]interface{}
 	OperationName   string
 	FinishReason    string
 	System          string
+	PromptTemplate  string
+	PromptVariables map[string]interface{}
+	StartTime       time.Time
+	EndTime         time.Time
+	Tags            []string
+	Metrics         map[string]float64
+	Sensitivity     SensitivityFlags
+
+	// Deprecated: use Usage, which takes precedence when set.
+	InputUsage *int
+	// Deprecated: use Usage, which takes precedence when set.
+	OutputUsage *int
+	// Deprecated: use Usage, which takes precedence when set.
+	TotalUsage *int
 }
 
 // CreateToolTraceOptions contains options for creating a tool trace.
+//
+// Error records a failed invocation: the span gets SpanTypeError and a
+// gen_ai.tool.call.error attribute. It cannot be combined with ToolOutput.
+//
+// ToolStartTime and ToolEndTime mark when the tool actually ran. They set
+// the span's timing when StartTime and EndTime are zero, and when both are
+// given the execution time is recorded as gen_ai.tool.execution_us. If
+// StartTime is also set, for example to when the call was queued, the wait
+// until ToolStartTime is recorded as gen_ai.tool.queue_us.
 type CreateToolTraceOptions struct {
+	TenantID        int64
+	ProjectID       int64
 	AgentID         int64
 	SessionID       int64
 	ToolName        string
 	ToolInput       map[string]interface{}
 	ToolOutput      map[string]interface{}
 	ToolDescription string
+	Progress        []ToolProgress
+	Error           error
+	ToolStartTime   time.Time
+	ToolEndTime     time.Time
 	ParentID        string
 	Metadata        map[string]interface{}
+	StartTime       time.Time
+	EndTime         time.Time
+	Tags            []string
+	Metrics         map[string]float64
+	Sensitivity     SensitivityFlags
+}
+
+// CreateDecisionTraceOptions contains options for creating a decision trace.
+type CreateDecisionTraceOptions struct {
+	TenantID     int64
+	ProjectID    int64
+	AgentID      int64
+	SessionID    int64
+	DecisionName string
+	ChosenBranch string
+	Candidates   []string
+	Reason       string
+	ParentID     string
+	Metadata     map[string]interface{}
+	StartTime    time.Time
+	EndTime      time.Time
+	Tags         []string
+	Metrics      map[string]float64
+	Sensitivity  SensitivityFlags
+}
+
+// CreateReasoningTraceOptions contains options for creating a reasoning
+// trace. Content is the model's reasoning text; StepNumber, when positive,
+// orders the steps of a plan.
+type CreateReasoningTraceOptions struct {
+	TenantID    int64
+	ProjectID   int64
+	AgentID     int64
+	SessionID   int64
+	Content     string
+	StepNumber  int
+	ParentID    string
+	Metadata    map[string]interface{}
+	StartTime   time.Time
+	EndTime     time.Time
+	Tags        []string
+	Metrics     map[string]float64
+	Sensitivity SensitivityFlags
+}
+
+// CreateEmbeddingTraceOptions contains options for creating an embedding
+// trace. InputCount is the number of texts embedded in the call and
+// Dimensions the size of each vector.
+type CreateEmbeddingTraceOptions struct {
+	TenantID    int64
+	ProjectID   int64
+	AgentID     int64
+	SessionID   int64
+	Model       string
+	System      string
+	InputCount  int
+	Dimensions  int
+	InputUsage  *int
+	ParentID    string
+	Metadata    map[string]interface{}
+	StartTime   time.Time
+	EndTime     time.Time
+	Tags        []string
+	Metrics     map[string]float64
+	Sensitivity SensitivityFlags
+}
+
+// RetrievedDoc is a document returned by a retrieval step.
+type RetrievedDoc struct {
+	ID      string  `json:"id"`
+	Score   float64 `json:"score"`
+	Content string  `json:"content,omitempty"`
+}
+
+// CreateRetrievalTraceOptions contains options for creating a retrieval
+// trace. TopK is the number of documents requested and defaults to the
+// number returned in Documents.
+type CreateRetrievalTraceOptions struct {
+	TenantID    int64
+	ProjectID   int64
+	AgentID     int64
+	SessionID   int64
+	Query       string
+	VectorStore string
+	TopK        int
+	Documents   []RetrievedDoc
+	ParentID    string
+	Metadata    map[string]interface{}
+	StartTime   time.Time
+	EndTime     time.Time
+	Tags        []string
+	Metrics     map[string]float64
+	Sensitivity SensitivityFlags
+}
+
+// CreateGuardrailTraceOptions contains options for creating a guardrail trace.
+// Categories holds per-category scores such as {"toxicity": 0.02}.
+type CreateGuardrailTraceOptions struct {
+	TenantID      int64
+	ProjectID     int64
+	AgentID       int64
+	SessionID     int64
+	GuardrailName string
+	Passed        bool
+	Categories    map[string]float64
+	Action        GuardrailAction
+	ParentID      string
+	Metadata      map[string]interface{}
+	StartTime     time.Time
+	EndTime       time.Time
+	Tags          []string
+	Metrics       map[string]float64
+	Sensitivity   SensitivityFlags
 }
 
 // UpdateTraceOptions contains options for updating a trace.
@@ -291,6 +782,20 @@ func nowMicroseconds() int64 {
 	return time.Now().UnixMicro()
 }
 
+// spanTimes returns span start and end timestamps in microseconds, using the
+// current time for whichever of start and end is zero.
+func spanTimes(start, end time.Time) (int64, int64) {
+	now := nowMicroseconds()
+	startUs, endUs := now, now
+	if !start.IsZero() {
+		startUs = start.UnixMicro()
+	}
+	if !end.IsZero() {
+		endUs = end.UnixMicro()
+	}
+	return startUs, endUs
+}
+
 // toJSON converts a value to JSON string.
 func toJSON(v interface{}) string {
 	b, err := json.Marshal(v)
@@ -0,0 +1,48 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+// WithSkipValidation turns off SpanInput validation in IngestBatch and the
+// Create* methods, for callers that build spans they know to be valid and
+// want to save the checks.
+func WithSkipValidation() ClientOption {
+	return func(c *Client) {
+		c.skipValidation = true
+	}
+}
+
+// Validate checks the span for mistakes the server would reject, returning a
+// *ValidationError naming the first offending field. It requires a SpanID,
+// a TraceID and an Attributes map, non-negative timestamps, an EndTime no
+// earlier than StartTime, and a ParentSpanID that is nil or non-empty.
+func (s *SpanInput) Validate() error {
+	switch {
+	case s.SpanID == "":
+		return &ValidationError{Field: "SpanID", Reason: "must not be empty"}
+	case s.TraceID == "":
+		return &ValidationError{Field: "TraceID", Reason: "must not be empty"}
+	case s.ParentSpanID != nil && *s.ParentSpanID == "":
+		return &ValidationError{Field: "ParentSpanID", Reason: "must be nil or non-empty"}
+	case s.StartTime < 0:
+		return &ValidationError{Field: "StartTime", Reason: "must not be negative"}
+	case s.EndTime != nil && *s.EndTime < 0:
+		return &ValidationError{Field: "EndTime", Reason: "must not be negative"}
+	case s.EndTime != nil && *s.EndTime < s.StartTime:
+		return &ValidationError{Field: "EndTime", Reason: "must not be before StartTime"}
+	case s.Attributes == nil:
+		return &ValidationError{Field: "Attributes", Reason: "must not be nil"}
+	}
+	return nil
+}
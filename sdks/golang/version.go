@@ -0,0 +1,110 @@
+// Copyright 2025 Sushanth (https://github.com/sushanthpy)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreplay
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// healthPath is the health endpoint, which WithVersionCheck itself uses and
+// so never gates.
+const healthPath = "/api/v1/health"
+
+// WithVersionCheck makes the client call Health before its first request
+// and fail with ErrServerTooOld if the server reports a version older than
+// minVersion, or no version at all. Versions are compared as dotted
+// numbers, e.g. "0.4.2"; a leading "v" and any pre-release or build suffix
+// are ignored.
+//
+// The outcome is cached, so the check runs once per client. A failed health
+// call is not cached: the request that triggered it fails and the next one
+// checks again.
+func WithVersionCheck(minVersion string) ClientOption {
+	return func(c *Client) {
+		c.minVersion = minVersion
+	}
+}
+
+// ServerVersion returns the version reported by the most recent successful
+// Health call, or "" if there has been none.
+func (c *Client) ServerVersion() string {
+	if v := c.serverVersion.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// checkVersion enforces WithVersionCheck ahead of a request to path.
+func (c *Client) checkVersion(ctx context.Context, path string) error {
+	if c.minVersion == "" || path == healthPath {
+		return nil
+	}
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if c.versionChecked {
+		return c.versionErr
+	}
+
+	health, err := c.Health(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check server version: %w", err)
+	}
+	c.versionChecked = true
+	if health.Version == "" {
+		c.versionErr = fmt.Errorf("%w: server did not report a version, need %s", ErrServerTooOld, c.minVersion)
+	} else if compareVersions(health.Version, c.minVersion) < 0 {
+		c.versionErr = fmt.Errorf("%w: server is %s, need %s", ErrServerTooOld, health.Version, c.minVersion)
+	}
+	return c.versionErr
+}
+
+// compareVersions compares two dotted version strings numerically,
+// returning -1, 0 or 1. Missing or non-numeric components count as zero.
+func compareVersions(a, b string) int {
+	as, bs := versionParts(a), versionParts(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts splits a version such as "v1.2.3-beta" into [1 2 3].
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	fields := strings.Split(version, ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts
+}